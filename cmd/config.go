@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"github.com/nedscode/nedward/config"
+	"github.com/nedscode/nedward/nedward"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate Nedward config",
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file and its imports for problems",
+	// Skip loading config, so problems can be reported in full
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configPath
+		if path == "" {
+			var err error
+			path, err = config.GetConfigPathFromWorkingDirectory()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		vars, err := parseVars()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		client, err := nedward.NewClient()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		client.Vars = vars
+		client.Profile = profile
+		return errors.WithStack(client.ValidateConfig(path))
+	},
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show [service|group]...",
+	Short: "Show the resolved config for services and groups",
+	Long: `Show the resolved config for services and groups, after imports have been loaded and paths and env
+combined. Shows all services and groups if none are specified.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WithStack(nedwardClient.ShowConfig(args))
+	},
+}
+
+// configSchemaCmd represents the config schema command
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Output a JSON Schema for Nedward config files",
+	// Skip loading config
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := nedward.NewClient()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(client.ConfigSchema())
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configSchemaCmd)
+}
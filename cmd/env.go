@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env <service>",
+	Short: "Show the environment for a service",
+	Long: `Show the environment variables that will be passed to a service, with where each was set.
+Variables are taken from the os environment, global env, group env (the nearest group winning), service env and
+--env, in increasing order of precedence.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WithStack(nedwardClient.ShowEnv(args[0], *envFlags.all))
+	},
+}
+
+var envFlags struct {
+	all *bool
+}
+
+func init() {
+	RootCmd.AddCommand(envCmd)
+
+	envFlags.all = envCmd.Flags().BoolP("all", "a", false, "Include variables inherited from the os environment")
+}
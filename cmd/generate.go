@@ -16,6 +16,9 @@ var generateCmd = &cobra.Command{
 				*generateFlags.force || *generateFlags.noPrompt,
 				*generateFlags.group,
 				*generateFlags.targets,
+				*generateFlags.merge,
+				*generateFlags.dryRun,
+				*generateFlags.output,
 			),
 		)
 	},
@@ -26,6 +29,9 @@ var generateFlags struct {
 	noPrompt *bool
 	group    *string
 	targets  *[]string
+	merge    *bool
+	dryRun   *bool
+	output   *string
 }
 
 func init() {
@@ -39,4 +45,10 @@ func init() {
 	generateFlags.group = generateCmd.Flags().StringP("group", "g", "", "Add newly generated services to a new or existing group.")
 
 	generateFlags.targets = generateCmd.Flags().StringArray("target", nil, "Explicitly specify a target for this generation. If no targets are given, all targets will be used.")
+
+	generateFlags.merge = generateCmd.Flags().BoolP("merge", "m", false, "Update the commands, launch check ports and watch settings of existing services.")
+
+	generateFlags.dryRun = generateCmd.Flags().Bool("dry-run", false, "Show a diff of the changes to the config file without writing them.")
+
+	generateFlags.output = generateCmd.Flags().StringP("output", "o", "", "Write the generated config to this file instead of the config file.")
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	lumberjack "gopkg.in/natefinch/lumberjack.v2"
 
@@ -67,9 +68,26 @@ Build, start and manage service instances with a single command.`,
 
 		command := cmd.Use
 
-		var err error
+		vars, err := parseVars()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, env := range envOverrides {
+			if !strings.Contains(env, "=") {
+				return errors.Errorf("invalid env %q, expected KEY=VALUE", env)
+			}
+		}
+
 		if command != "generate" {
-			nedwardClient, err = nedward.NewClientWithConfig(configPath, common.NedwardVersion, logger)
+			nedwardClient, err = nedward.NewClient()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			nedwardClient.Config = configPath
+			nedwardClient.Logger = logger
+			nedwardClient.Vars = vars
+			nedwardClient.Profile = profile
+			err = nedwardClient.LoadConfig(common.NedwardVersion)
 			if err != nil {
 				return errors.WithStack(err)
 			}
@@ -89,6 +107,9 @@ Build, start and manage service instances with a single command.`,
 			return errors.WithStack(sudoIfNeeded(sgs))
 		}
 		nedwardClient.Logger = logger
+		nedwardClient.Vars = vars
+		nedwardClient.Profile = profile
+		nedwardClient.Env = envOverrides
 		// Populate the Nedward executable with this binary
 		nedwardClient.NedwardExecutable = os.Args[0]
 
@@ -163,12 +184,18 @@ func Execute() {
 var configPath string
 var redirectLogs bool
 var logFile string
+var varFlags []string
+var envOverrides []string
+var profile string
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	RootCmd.PersistentFlags().StringVar(&logFile, "logfile", "", "Write logs to `PATH`")
 	RootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Use service configuration file at `PATH`")
+	RootCmd.PersistentFlags().StringArrayVar(&varFlags, "var", nil, "Set the config variable `NAME=VALUE`, may be repeated")
+	RootCmd.PersistentFlags().StringArrayVar(&envOverrides, "env", nil, "Set the environment variable `KEY=VALUE` for services, overriding config, may be repeated")
+	RootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Apply the profile `NAME` to services and groups that define it")
 	RootCmd.PersistentFlags().BoolVar(&redirectLogs, "redirect_logs", false, "Redirect edward logs to the console")
 	err := RootCmd.PersistentFlags().MarkHidden("redirect_logs")
 	if err != nil {
@@ -180,6 +207,22 @@ func init() {
 	}
 }
 
+// parseVars returns the values of config variables set with --var
+func parseVars() (map[string]string, error) {
+	if len(varFlags) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string)
+	for _, v := range varFlags {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid variable %q, expected NAME=VALUE", v)
+		}
+		vars[parts[0]] = parts[1]
+	}
+	return vars, nil
+}
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	if cfgFile != "" {
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// secretsCmd represents the secrets command
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage secrets referenced from env with from_secret",
+	Long: `Manage the encrypted secrets file stored alongside the config file. Secrets are encrypted with a key
+stored in the Nedward home directory, which is created when the first secret is set.`,
+}
+
+// secretsSetCmd represents the secrets set command
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Set a secret",
+	Long:  `Set a secret. If no value is given, it is read from stdin, so it need not appear in your shell history.`,
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 2 {
+			return errors.WithStack(nedwardClient.SetSecret(args[0], args[1]))
+		}
+		value, err := ioutil.ReadAll(nedwardClient.Input)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(nedwardClient.SetSecret(args[0], strings.TrimRight(string(value), "\r\n")))
+	},
+}
+
+// secretsGetCmd represents the secrets get command
+var secretsGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print the value of a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WithStack(nedwardClient.GetSecret(args[0]))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsGetCmd)
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -21,10 +20,14 @@ import (
 type Config struct {
 	workingDir        string
 	MinNedwardVersion string                   `json:"nedwardVersion,omitempty"`
-	Imports           []string                 `json:"imports,omitempty"`
+	Imports           []Import                 `json:"imports,omitempty"`
 	ImportedGroups    []GroupDef               `json:"-"`
 	ImportedServices  []services.ServiceConfig `json:"-"`
 	Env               []string                 `json:"env,omitempty"`
+	EnvFile           services.EnvFiles        `json:"env_file,omitempty"`
+	SecretEnv         []services.EnvSecret     `json:"-"`
+	Vars              map[string]string        `json:"vars,omitempty"`
+	ImportedVars      map[string]string        `json:"-"`
 	Groups            []GroupDef               `json:"groups,omitempty"`
 	Services          []services.ServiceConfig `json:"services"`
 
@@ -33,6 +36,44 @@ type Config struct {
 
 	Logger   common.Logger `json:"-"`
 	FilePath string        `json:"-"`
+
+	options LoadOptions
+	// Values of config variables, resolved when the config is loaded
+	vars map[string]string
+}
+
+// Import identifies a config file, or files matching a glob pattern, to be imported.
+// In config, it may be specified as a path, or an object with a path and namespace.
+type Import struct {
+	Path string `json:"path"`
+	// Prefix for the names of services and groups from the imported files, for example
+	// "payments" to import "api" as "payments/api"
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// UnmarshalJSON accepts either a path or an object with a path and namespace
+func (i *Import) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*i = Import{Path: path}
+		return nil
+	}
+	type Alias Import
+	var aux Alias
+	if err := json.Unmarshal(data, &aux); err != nil || aux.Path == "" {
+		return errors.New("imports must be paths, or objects with a path and optional namespace")
+	}
+	*i = Import(aux)
+	return nil
+}
+
+// MarshalJSON writes imports without a namespace as a path alone
+func (i Import) MarshalJSON() ([]byte, error) {
+	if i.Namespace == "" {
+		return json.Marshal(i.Path)
+	}
+	type Alias Import
+	return json.Marshal(Alias(i))
 }
 
 // GroupDef defines a group based on a list of children specified by name
@@ -40,12 +81,138 @@ type GroupDef struct {
 	Name        string   `json:"name"`
 	Aliases     []string `json:"aliases,omitempty"`
 	Description string   `json:"description,omitempty"`
-	Children    []string `json:"children"`
-	Env         []string `json:"env,omitempty"`
+	Children    []string          `json:"children"`
+	Env         []string          `json:"env,omitempty"`
+	EnvFile     services.EnvFiles `json:"env_file,omitempty"`
+	// Variables whose values are resolved at launch, set as objects in env
+	SecretEnv []services.EnvSecret `json:"-"`
+	// Alternate env for this group, by profile name
+	Profiles map[string]GroupProfile `json:"profiles,omitempty"`
+
+	// Path to the config file in which this group was defined
+	SourceFile string `json:"-"`
+}
+
+// GroupProfile holds alternate env for a group, applied when the named profile is selected
+type GroupProfile struct {
+	// Environment variables added to those of the group, taking precedence over them
+	Env []string `json:"env,omitempty"`
+	// Env files read after those of the group
+	EnvFile services.EnvFiles `json:"env_file,omitempty"`
+	// Variables whose values are resolved at launch, set as objects in env
+	SecretEnv []services.EnvSecret `json:"-"`
+}
+
+// UnmarshalJSON separates secrets from variables set inline in the env array
+func (p *GroupProfile) UnmarshalJSON(data []byte) error {
+	type Alias GroupProfile
+	aux := &struct {
+		Env []json.RawMessage `json:"env,omitempty"`
+		*Alias
+	}{
+		Alias: (*Alias)(p),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	p.Env, p.SecretEnv, err = services.DecodeEnv(aux.Env)
+	return errors.WithStack(err)
+}
+
+// MarshalJSON writes secrets to the env array alongside variables set inline
+func (p GroupProfile) MarshalJSON() ([]byte, error) {
+	type Alias GroupProfile
+	return json.Marshal(&struct {
+		Env []interface{} `json:"env,omitempty"`
+		Alias
+	}{
+		Env:   services.EncodeEnv(p.Env, p.SecretEnv),
+		Alias: Alias(p),
+	})
+}
+
+// applyProfile returns a copy of this group with the settings from the named profile applied,
+// if the group defines it, along with whether it does.
+func (g GroupDef) applyProfile(name string) (GroupDef, bool) {
+	profile, ok := g.Profiles[name]
+	if !ok {
+		return g, false
+	}
+	g.Env = append(append([]string{}, g.Env...), profile.Env...)
+	g.EnvFile = append(append(services.EnvFiles{}, g.EnvFile...), profile.EnvFile...)
+	g.SecretEnv = append(append([]services.EnvSecret{}, g.SecretEnv...), profile.SecretEnv...)
+	return g, true
+}
+
+// MarshalJSON writes secrets to the env array alongside variables set inline
+func (g GroupDef) MarshalJSON() ([]byte, error) {
+	type Alias GroupDef
+	return json.Marshal(&struct {
+		Env []interface{} `json:"env,omitempty"`
+		Alias
+	}{
+		Env:   services.EncodeEnv(g.Env, g.SecretEnv),
+		Alias: Alias(g),
+	})
+}
+
+// UnmarshalJSON separates secrets from variables set inline in the env arrays of the config and its groups
+func (c *Config) UnmarshalJSON(data []byte) error {
+	// Local types are named after those they decode, so errors refer to the expected fields
+	type configAlias Config
+	type groupAlias GroupDef
+	type groupDef = GroupDef
+	type GroupDef struct {
+		Env []json.RawMessage `json:"env,omitempty"`
+		groupAlias
+	}
+	type Config struct {
+		Env    []json.RawMessage `json:"env,omitempty"`
+		Groups []GroupDef        `json:"groups,omitempty"`
+		*configAlias
+	}
+	aux := &Config{configAlias: (*configAlias)(c)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	var err error
+	c.Env, c.SecretEnv, err = services.DecodeEnv(aux.Env)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Groups = nil
+	for _, g := range aux.Groups {
+		group := groupDef(g.groupAlias)
+		group.Env, group.SecretEnv, err = services.DecodeEnv(g.Env)
+		if err != nil {
+			return errors.WithMessage(err, "group "+group.Name)
+		}
+		c.Groups = append(c.Groups, group)
+	}
+	return nil
+}
+
+// MarshalJSON writes secrets to the env array alongside variables set inline
+func (c Config) MarshalJSON() ([]byte, error) {
+	type Alias Config
+	return json.Marshal(&struct {
+		Env []interface{} `json:"env,omitempty"`
+		Alias
+	}{
+		Env:   services.EncodeEnv(c.Env, c.SecretEnv),
+		Alias: Alias(c),
+	})
 }
 
 // LoadConfig loads configuration from an io.Reader with the working directory explicitly specified
 func LoadConfig(filePath string, nedwardVersion string, logger common.Logger) (Config, error) {
+	return LoadConfigWithOptions(filePath, nedwardVersion, logger, LoadOptions{})
+}
+
+// LoadConfigWithOptions loads configuration as LoadConfig, applying settings that are not specified
+// in the config file, such as values for config variables.
+func LoadConfigWithOptions(filePath string, nedwardVersion string, logger common.Logger, options LoadOptions) (Config, error) {
 	if logger != nil {
 		logger.Printf("Loading config from: %s\n", filePath)
 	}
@@ -53,9 +220,7 @@ func LoadConfig(filePath string, nedwardVersion string, logger common.Logger) (C
 	if err != nil {
 		return Config{}, errors.WithStack(err)
 	}
-	workingDir := path.Dir(filePath)
-	config, err := loadConfigContents(reader, workingDir, logger)
-	config.FilePath = filePath
+	config, err := loadConfigContents(reader, filePath, logger)
 	if err != nil {
 		return Config{}, errors.WithStack(err)
 	}
@@ -73,6 +238,7 @@ func LoadConfig(filePath string, nedwardVersion string, logger common.Logger) (C
 			return Config{}, errors.New("this config requires at least version " + config.MinNedwardVersion)
 		}
 	}
+	config.options = options
 	err = config.initMaps()
 
 	config.printf("Config loaded with: %d groups and %d services\n", len(config.GroupMap), len(config.ServiceMap))
@@ -80,7 +246,18 @@ func LoadConfig(filePath string, nedwardVersion string, logger common.Logger) (C
 }
 
 // Reader from os.Open
-func loadConfigContents(reader io.Reader, workingDir string, logger common.Logger) (Config, error) {
+func loadConfigContents(reader io.Reader, filePath string, logger common.Logger) (Config, error) {
+	config, err := readConfigContents(reader, filePath, logger)
+	if err != nil {
+		return Config{}, errors.WithStack(err)
+	}
+	err = config.loadImports([]string{filePath})
+	return config, errors.WithStack(err)
+}
+
+// readConfigContents reads a single config file, without loading its imports
+func readConfigContents(reader io.Reader, filePath string, logger common.Logger) (Config, error) {
+	workingDir := filepath.Dir(filePath)
 	log := common.MaskLogger(logger)
 	log.Printf("Loading config with working dir %v.\n", workingDir)
 
@@ -90,37 +267,36 @@ func loadConfigContents(reader io.Reader, workingDir string, logger common.Logge
 		return Config{}, errors.Wrap(err, "could not read config")
 	}
 
-	data := buf.Bytes()
 	var config Config
-	err = json.Unmarshal(data, &config)
+	_, err = decodeConfig(buf.Bytes(), formatForPath(filePath), &config)
 	if err != nil {
-		if syntax, ok := err.(*json.SyntaxError); ok && syntax.Offset != 0 {
-			start := strings.LastIndex(string(data[:syntax.Offset]), "\n") + 1
-			line, pos := strings.Count(string(data[:start]), "\n")+1, int(syntax.Offset)-start-1
-			return Config{}, errors.Wrapf(err, "could not parse config file (line %v, char %v)", line, pos)
-		}
-		return Config{}, errors.Wrap(err, "could not parse config file")
+		return Config{}, errors.WithStack(err)
 	}
 
 	config.workingDir = workingDir
-
-	err = config.loadImports()
-	if err != nil {
-		return Config{}, errors.WithStack(err)
+	config.FilePath = filePath
+	for i := range config.Services {
+		config.Services[i].SourceFile = filePath
+	}
+	for i := range config.Groups {
+		config.Groups[i].SourceFile = filePath
 	}
 
 	config.Logger = log
-
 	return config, nil
 }
 
-// Save saves config to an io.Writer
+// Save saves config to an io.Writer, in the format of the file from which it was loaded
 func (c Config) Save(writer io.Writer) error {
 	c.printf("Saving config")
 	content, err := json.MarshalIndent(c, "", "    ")
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	content, err = encodeConfig(content, formatForPath(c.FilePath))
+	if err != nil {
+		return errors.WithStack(err)
+	}
 	_, err = writer.Write(content)
 	return errors.WithStack(err)
 }
@@ -209,6 +385,103 @@ func (c *Config) AppendServices(newServices []*services.ServiceConfig) error {
 	return nil
 }
 
+// ServiceChange describes a change made to a field of an existing service by MergeServices
+type ServiceChange struct {
+	Service string
+	Field   string
+	From    string
+	To      string
+}
+
+func (c ServiceChange) String() string {
+	return fmt.Sprintf("%v: %v changed from %q to %q", c.Service, c.Field, c.From, c.To)
+}
+
+// MergeServices updates the generator-owned fields of services in this config that have the same
+// name as any of newServices: commands, launch check ports and watch. Fields that are not set in the
+// new services, and all other fields, are left as they are.
+// Services that are only defined in imported config files are not changed.
+// Returns a list of the fields that were changed.
+func (c *Config) MergeServices(newServices []*services.ServiceConfig) ([]ServiceChange, error) {
+	c.printf("Merging %d services.\n", len(newServices))
+	var changes []ServiceChange
+	for _, s := range newServices {
+		for i := range c.Services {
+			existing := &c.Services[i]
+			if existing.Name != s.Name {
+				continue
+			}
+			serviceChanges, err := mergeService(existing, s)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if mapped, ok := c.ServiceMap[s.Name]; ok {
+				_, err = mergeService(mapped, s)
+				if err != nil {
+					return nil, errors.WithStack(err)
+				}
+			}
+			changes = append(changes, serviceChanges...)
+		}
+	}
+	return changes, nil
+}
+
+// mergeService applies the generator-owned fields of generated to existing
+func mergeService(existing, generated *services.ServiceConfig) ([]ServiceChange, error) {
+	var changes []ServiceChange
+	mergeField := func(field string, current *string, value string) {
+		if value != "" && *current != value {
+			changes = append(changes, ServiceChange{Service: existing.Name, Field: field, From: *current, To: value})
+			*current = value
+		}
+	}
+	mergeField("commands.install", &existing.Commands.Install, generated.Commands.Install)
+	mergeField("commands.update", &existing.Commands.Update, generated.Commands.Update)
+	mergeField("commands.build", &existing.Commands.Build, generated.Commands.Build)
+	mergeField("commands.launch", &existing.Commands.Launch, generated.Commands.Launch)
+	mergeField("commands.stop", &existing.Commands.Stop, generated.Commands.Stop)
+
+	if generated.LaunchChecks != nil && len(generated.LaunchChecks.Ports) > 0 {
+		var from []int
+		if existing.LaunchChecks != nil {
+			from = existing.LaunchChecks.Ports
+		}
+		if fmt.Sprint(from) != fmt.Sprint(generated.LaunchChecks.Ports) {
+			changes = append(changes, ServiceChange{
+				Service: existing.Name,
+				Field:   "launch_checks.ports",
+				From:    fmt.Sprint(from),
+				To:      fmt.Sprint(generated.LaunchChecks.Ports),
+			})
+			if existing.LaunchChecks == nil {
+				existing.LaunchChecks = &services.LaunchChecks{}
+			} else {
+				checks := *existing.LaunchChecks
+				existing.LaunchChecks = &checks
+			}
+			existing.LaunchChecks.Ports = generated.LaunchChecks.Ports
+		}
+	}
+
+	if len(generated.WatchJSON) > 0 {
+		var from, to bytes.Buffer
+		if len(existing.WatchJSON) > 0 {
+			if err := json.Compact(&from, existing.WatchJSON); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		if err := json.Compact(&to, generated.WatchJSON); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if from.String() != to.String() {
+			changes = append(changes, ServiceChange{Service: existing.Name, Field: "watch", From: from.String(), To: to.String()})
+			existing.WatchJSON = generated.WatchJSON
+		}
+	}
+	return changes, nil
+}
+
 // AppendGroups adds groups to an existing config without replacing existing groups
 func (c *Config) AppendGroups(groups []*services.ServiceGroupConfig) error {
 	var groupsDereferenced []services.ServiceGroupConfig
@@ -260,41 +533,120 @@ func (c *Config) AddGroups(groups []services.ServiceGroupConfig) error {
 	return nil
 }
 
-func (c *Config) loadImports() error {
+// loadImports loads the files imported by this config, along with any files they import in turn.
+// The chain lists the files through which this config was imported, ending with this config
+// itself, so that cycles can be detected.
+func (c *Config) loadImports(chain []string) error {
 	c.printf("Loading imports\n")
-	for _, i := range c.Imports {
-		var cPath string
-		if filepath.IsAbs(i) {
-			cPath = i
-		} else {
-			cPath = filepath.Join(c.workingDir, i)
+	for _, imported := range c.Imports {
+		i := imported.Path
+		paths, err := c.importPaths(i, chain[len(chain)-1])
+		if err != nil {
+			return errors.WithStack(err)
 		}
+		for _, cPath := range paths {
+			if err := checkImportCycle(chain, cPath); err != nil {
+				return errors.WithStack(err)
+			}
 
-		c.printf("Loading: %v\n", cPath)
+			c.printf("Loading: %v\n", cPath)
 
-		r, err := os.Open(cPath)
-		if err != nil {
-			return errors.WithStack(err)
+			r, err := os.Open(cPath)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			cfg, err := readConfigContents(r, cPath, c.Logger)
+			r.Close()
+			if err != nil {
+				return errors.WithMessage(err, i)
+			}
+			if err = cfg.loadImports(append(append([]string{}, chain...), cPath)); err != nil {
+				return errors.WithStack(err)
+			}
+
+			err = c.importConfig(cfg, imported.Namespace)
+			if err != nil {
+				return errors.WithStack(err)
+			}
 		}
-		cfg, err := loadConfigContents(r, filepath.Dir(cPath), c.Logger)
-		if err != nil {
-			return errors.WithMessage(err, i)
+	}
+	return nil
+}
+
+// importPaths returns the paths to the files matched by an entry in imports, relative to the
+// directory of the importing config. Entries containing glob patterns may match any number of
+// files, excluding the importing config itself, but must match at least one.
+func (c *Config) importPaths(i string, importer string) ([]string, error) {
+	cPath := i
+	if !filepath.IsAbs(i) {
+		cPath = filepath.Join(c.workingDir, i)
+	}
+	if !isGlob(i) {
+		return []string{cPath}, nil
+	}
+	matches, err := filepath.Glob(cPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, i)
+	}
+	var paths []string
+	for _, match := range matches {
+		if !sameFile(match, importer) {
+			paths = append(paths, match)
 		}
+	}
+	if len(paths) == 0 {
+		return nil, errors.Errorf("no files match import %v", i)
+	}
+	return paths, nil
+}
 
-		err = c.importConfig(cfg)
-		if err != nil {
-			return errors.WithStack(err)
+// isGlob returns true if path contains any of the special characters recognized by filepath.Match
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// checkImportCycle returns an error listing the chain of imports if path is already in chain
+func checkImportCycle(chain []string, path string) error {
+	for i, imported := range chain {
+		if sameFile(imported, path) {
+			cycle := append(append([]string{}, chain[i:]...), path)
+			return errors.Errorf("import cycle: %v", strings.Join(cycle, " -> "))
 		}
 	}
 	return nil
 }
 
-func (c *Config) importConfig(second Config) error {
-	for _, service := range second.Services {
-		c.ImportedServices = append(c.ImportedServices, service)
+// sameFile returns true if the paths a and b refer to the same file
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
 	}
-	for _, group := range second.Groups {
-		c.ImportedGroups = append(c.ImportedGroups, group)
+	return absA == absB
+}
+
+// importConfig adds the services, groups and variables from an imported config, including
+// those it imported in turn. If namespace is set, the names of services and groups are
+// prefixed with it.
+func (c *Config) importConfig(second Config, namespace string) error {
+	if namespace != "" {
+		second = second.withNamespace(namespace)
+	}
+	c.ImportedServices = append(c.ImportedServices, second.Services...)
+	c.ImportedServices = append(c.ImportedServices, second.ImportedServices...)
+	c.ImportedGroups = append(c.ImportedGroups, second.Groups...)
+	c.ImportedGroups = append(c.ImportedGroups, second.ImportedGroups...)
+	// Variables from earlier imports take precedence, and those set in a file over those it imports
+	for _, vars := range []map[string]string{second.Vars, second.ImportedVars} {
+		for key, value := range vars {
+			if c.ImportedVars == nil {
+				c.ImportedVars = make(map[string]string)
+			}
+			if _, exists := c.ImportedVars[key]; !exists {
+				c.ImportedVars[key] = value
+			}
+		}
 	}
 	return nil
 }
@@ -307,6 +659,21 @@ func (c *Config) combinePath(path string) *string {
 	return &fullPath
 }
 
+// envFilePaths resolves paths to env files relative to the config file in which they were specified
+func envFilePaths(paths []string, sourceFile string) []string {
+	var out []string
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(sourceFile), path)
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		out = append(out, path)
+	}
+	return out
+}
+
 func addToMap(m map[string]struct{}, values ...string) {
 	for _, v := range values {
 		m[v] = struct{}{}
@@ -331,10 +698,28 @@ func (c *Config) initMaps() error {
 
 	var namesInUse = make(map[string]struct{})
 
+	c.vars = c.resolveVars()
+	env := interpolateAll(c.Env, c.vars)
+	envFiles := envFilePaths(interpolateAll(c.EnvFile, c.vars), c.FilePath)
+	secretEnv := interpolateSecrets(c.SecretEnv, c.vars)
+	// Whether the selected profile is defined by any service or group
+	profileDefined := false
+
 	for _, s := range append(c.Services, c.ImportedServices...) {
 		sc := s
 		sc.Logger = c.Logger
-		sc.Env = append(sc.Env, c.Env...)
+		if c.options.Profile != "" && sc.ApplyProfile(c.options.Profile) {
+			profileDefined = true
+		}
+		if err = interpolateService(&sc, c.vars); err != nil {
+			return errors.WithMessage(err, sc.Name)
+		}
+		sc.GlobalEnv = env
+		sc.GlobalEnvFile = envFiles
+		sc.GlobalSecretEnv = secretEnv
+		if len(sc.EnvFile) > 0 {
+			sc.EnvFile = envFilePaths(sc.EnvFile, sc.SourceFile)
+		}
 		sc.ConfigFile, err = filepath.Abs(c.FilePath)
 		if err != nil {
 			return errors.WithStack(err)
@@ -350,11 +735,22 @@ func (c *Config) initMaps() error {
 		}
 	}
 
+	groupDefs, err := c.resolveChildren(svcs, servicesSkipped)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	var groups = make(map[string]*services.ServiceGroupConfig)
 	// First pass: Services
 	var orphanNames = make(map[string]struct{})
-	for _, g := range append(c.Groups, c.ImportedGroups...) {
+	for _, g := range groupDefs {
 		var childServices []*services.ServiceConfig
+		if c.options.Profile != "" {
+			var defined bool
+			if g, defined = g.applyProfile(c.options.Profile); defined {
+				profileDefined = true
+			}
+		}
 
 		for _, name := range g.Children {
 			if s, ok := svcs[name]; ok {
@@ -377,7 +773,9 @@ func (c *Config) initMaps() error {
 			Description: g.Description,
 			Services:    childServices,
 			Groups:      []*services.ServiceGroupConfig{},
-			Env:         g.Env,
+			Env:         interpolateAll(g.Env, c.vars),
+			EnvFile:     envFilePaths(interpolateAll(g.EnvFile, c.vars), g.SourceFile),
+			SecretEnv:   interpolateSecrets(g.SecretEnv, c.vars),
 			Logger:      c.Logger,
 			ChildOrder:  g.Children,
 		}
@@ -385,7 +783,7 @@ func (c *Config) initMaps() error {
 	}
 
 	// Second pass: Groups
-	for _, g := range append(c.Groups, c.ImportedGroups...) {
+	for _, g := range groupDefs {
 		childGroups := []*services.ServiceGroupConfig{}
 
 		for _, name := range g.Children {
@@ -400,6 +798,10 @@ func (c *Config) initMaps() error {
 		groups[g.Name].Groups = childGroups
 	}
 
+	if c.options.Profile != "" && !profileDefined {
+		return errors.Errorf("profile %v is not defined by any service or group", c.options.Profile)
+	}
+
 	if len(orphanNames) > 0 {
 		var keys []string
 		for k := range orphanNames {
@@ -413,6 +815,39 @@ func (c *Config) initMaps() error {
 	return nil
 }
 
+// resolveChildren returns the definitions of all groups, with the names of their children resolved
+// to the full names of services and groups, so children from namespaced imports may be referred
+// to without their namespace where this is unambiguous.
+func (c *Config) resolveChildren(svcs map[string]*services.ServiceConfig, skipped map[string]struct{}) ([]GroupDef, error) {
+	var names []string
+	for name := range svcs {
+		names = append(names, name)
+	}
+	for name := range skipped {
+		names = append(names, name)
+	}
+	defs := append(append([]GroupDef{}, c.Groups...), c.ImportedGroups...)
+	for _, g := range defs {
+		names = append(names, g.Name)
+	}
+
+	for i, g := range defs {
+		var children []string
+		for _, child := range g.Children {
+			resolved, err := services.ResolveName(child, names)
+			if err != nil {
+				return nil, errors.WithMessage(err, "group "+g.Name)
+			}
+			if resolved == "" {
+				resolved = child
+			}
+			children = append(children, resolved)
+		}
+		defs[i].Children = children
+	}
+	return defs, nil
+}
+
 func hasChildCycle(parent *services.ServiceGroupConfig, children []*services.ServiceGroupConfig) bool {
 	for _, sg := range children {
 		if parent == sg {
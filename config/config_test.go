@@ -1,9 +1,13 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	must "github.com/theothertomelliott/must"
@@ -110,10 +114,16 @@ var fileBasedTests = []struct {
 	outServiceMap map[string]*services.ServiceConfig
 	outGroupMap   map[string]*services.ServiceGroupConfig
 	outErr        error
+	// Files from which services were loaded, if not inFile
+	sourceFiles map[string]string
 }{
 	{
 		name:   "Config with imports",
 		inFile: "test1.json",
+		sourceFiles: map[string]string{
+			"service2": "imports1/import1.json",
+			"service3": "imports1/import2.json",
+		},
 		outServiceMap: map[string]*services.ServiceConfig{
 			"service1": &service1,
 			"service2": &service2,
@@ -176,16 +186,88 @@ var fileBasedTests = []struct {
 		inFile: "bad.json",
 		outErr: errors.New("could not parse config file (line 7, char 9): invalid character ':' after array element"),
 	},
+	{
+		name:   "Invalid value in json",
+		inFile: "badtype.json",
+		outErr: errors.New("could not parse config file (line 13): json: cannot unmarshal string into Go struct field Config.groups.0.children of type []string"),
+	},
+	{
+		name:   "YAML config with mixed imports",
+		inFile: "test10.yaml",
+		sourceFiles: map[string]string{
+			"service2": "imports10/import1.toml",
+			"service3": "imports1/import2.json",
+		},
+		outServiceMap: map[string]*services.ServiceConfig{
+			"service1": &service1,
+			"service2": &service2,
+			"service3": &service3,
+		},
+		outGroupMap: map[string]*services.ServiceGroupConfig{
+			"group1": &group1,
+			"group2": &group2,
+			"group3": &group3,
+		},
+	},
+	{
+		name:   "Invalid yaml",
+		inFile: "bad.yaml",
+		outErr: errors.New("could not parse config file (line 7): found unexpected end of stream"),
+	},
+	{
+		name:   "Invalid value in yaml",
+		inFile: "badtype.yaml",
+		outErr: errors.New("could not parse config file (line 11): could not parse service config: json: cannot unmarshal string into .launch_checks.ports.1 of type int"),
+	},
+	{
+		name:   "TOML config with mixed imports",
+		inFile: "test11.toml",
+		sourceFiles: map[string]string{
+			"service2": "imports1/import1.json",
+			"service3": "imports10/import2.yaml",
+		},
+		outServiceMap: map[string]*services.ServiceConfig{
+			"service1": &service1,
+			"service2": &service2,
+			"service3": &service3,
+		},
+		outGroupMap: map[string]*services.ServiceGroupConfig{
+			"group1": &group1,
+			"group2": &group2,
+			"group3": &group3,
+		},
+	},
+	{
+		name:   "Invalid toml",
+		inFile: "bad.toml",
+		outErr: errors.New("could not parse config file (line 5, char 12): keys cannot contain new lines"),
+	},
+	{
+		name:   "Invalid value in toml",
+		inFile: "badtype.toml",
+		outErr: errors.New("could not parse config file (line 9): could not parse service config: json: cannot unmarshal string into Go struct field .requiresSudo of type bool"),
+	},
 }
 
 func TestLoadConfigWithImports(t *testing.T) {
-	err := os.Chdir("testdata")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	err = os.Chdir("testdata")
 	if err != nil {
 		t.Errorf("%v", err)
 		return
 	}
 	for _, test := range fileBasedTests {
 		cfg, err := LoadConfig(test.inFile, "", nil)
+		for name, s := range test.outServiceMap {
+			s.SourceFile = test.inFile
+			if file, ok := test.sourceFiles[name]; ok {
+				s.SourceFile = file
+			}
+		}
 		validateTestResults(cfg, err, test.inFile, test.outServiceMap, test.outGroupMap, test.outErr, test.name, t)
 	}
 }
@@ -199,3 +281,332 @@ func validateTestResults(cfg Config, err error, file string, expectedServices ma
 
 	must.BeEqualErrors(t, expectedErr, err, name+": Errors did not match.")
 }
+
+func TestMergeServices(t *testing.T) {
+	cfg := Config{
+		Services: []services.ServiceConfig{
+			{
+				Name: "service1",
+				Commands: services.ServiceConfigCommands{
+					Build:  "make",
+					Launch: "./service1",
+					Stop:   "./stop.sh",
+				},
+				LaunchChecks: &services.LaunchChecks{
+					LogText: "started",
+					Ports:   []int{8080},
+				},
+				Env: []string{"HAND=edited"},
+			},
+			{
+				Name: "service2",
+				Commands: services.ServiceConfigCommands{
+					Launch: "./service2",
+				},
+				WatchJSON: []byte(`{ "include": ["service2"] }`),
+			},
+		},
+		Logger: common.NullLogger{},
+	}
+
+	changes, err := cfg.MergeServices([]*services.ServiceConfig{
+		{
+			Name: "service1",
+			Commands: services.ServiceConfigCommands{
+				Build:  "make",
+				Launch: "./bin/service1",
+			},
+			LaunchChecks: &services.LaunchChecks{
+				Ports: []int{8080, 9090},
+			},
+			Env: []string{},
+		},
+		{
+			Name: "service2",
+			Commands: services.ServiceConfigCommands{
+				Launch: "./service2",
+			},
+			WatchJSON: []byte(`{"include":["service2"]}`),
+		},
+		{
+			Name: "service3",
+			Commands: services.ServiceConfigCommands{
+				Launch: "./service3",
+			},
+		},
+	})
+	must.BeNoError(t, err)
+	must.BeEqual(t, []ServiceChange{
+		{Service: "service1", Field: "commands.launch", From: "./service1", To: "./bin/service1"},
+		{Service: "service1", Field: "launch_checks.ports", From: "[8080]", To: "[8080 9090]"},
+	}, changes)
+
+	service1 := cfg.Services[0]
+	must.BeEqual(t, "make", service1.Commands.Build)
+	must.BeEqual(t, "./bin/service1", service1.Commands.Launch)
+	must.BeEqual(t, "./stop.sh", service1.Commands.Stop)
+	must.BeEqual(t, &services.LaunchChecks{LogText: "started", Ports: []int{8080, 9090}}, service1.LaunchChecks)
+	must.BeEqual(t, []string{"HAND=edited"}, service1.Env)
+	must.BeEqual(t, 2, len(cfg.Services))
+}
+
+func TestSaveFormats(t *testing.T) {
+	for _, name := range []string{"nedward.json", "nedward.yaml", "nedward.toml"} {
+		cfg := Config{
+			Env: []string{"GLOBAL=value"},
+			Services: []services.ServiceConfig{
+				{
+					Name: "service1",
+					Path: common.StringToStringPointer("service1"),
+					Commands: services.ServiceConfigCommands{
+						Launch: "./service1",
+					},
+					LaunchChecks: &services.LaunchChecks{
+						Ports: []int{8080},
+					},
+					WatchJSON: []byte(`{"include":["service1"]}`),
+				},
+			},
+			Groups: []GroupDef{
+				{Name: "group1", Children: []string{"service1"}},
+			},
+			Logger:   common.NullLogger{},
+			FilePath: name,
+		}
+
+		var content bytes.Buffer
+		err := cfg.Save(&content)
+		must.BeNoError(t, err, name)
+
+		var loaded Config
+		_, err = decodeConfig(content.Bytes(), formatForPath(name), &loaded)
+		must.BeNoError(t, err, name)
+		must.BeEqual(t, cfg.Env, loaded.Env, name)
+		must.BeEqual(t, cfg.Groups, loaded.Groups, name)
+		must.BeEqual(t, 1, len(loaded.Services), name)
+		must.BeEqual(t, cfg.Services[0].Commands, loaded.Services[0].Commands, name)
+		must.BeEqual(t, cfg.Services[0].LaunchChecks, loaded.Services[0].LaunchChecks, name)
+		var watch bytes.Buffer
+		must.BeNoError(t, json.Compact(&watch, loaded.Services[0].WatchJSON), name)
+		must.BeEqual(t, string(cfg.Services[0].WatchJSON), watch.String(), name)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	problems, err := Validate(filepath.Join("testdata", "validate", "nedward.yaml"), LoadOptions{})
+	must.BeNoError(t, err)
+
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.String())
+	}
+	must.BeEqual(t, []string{
+		"testdata/validate/nedward.yaml:3: imports[1]: could not import testdata/validate/missing.json: no such file or directory",
+		"testdata/validate/nedward.yaml:7: services[0].comands: unknown key \"comands\", did you mean \"commands\"?",
+		"testdata/validate/nedward.yaml:9: services[0].platform: unknown value \"darwn\", did you mean \"darwin\"?",
+		"testdata/validate/nedward.yaml:13: services[0].launch_checks.ports[1]: 70000 is greater than the maximum of 65535",
+		"testdata/validate/nedward.yaml:19: services[1].watch.exclude: expected an array, found true",
+		"testdata/validate/services.json:15: services[1].launch_checks.ports[0]: expected an integer, found \"8081\"",
+		"testdata/validate/nedward.yaml:24: groups[0].children[1]: service worker in group all has no launch command",
+	}, got)
+}
+
+func TestShow(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join("testdata", "show", "nedward.json"), "", nil)
+	must.BeNoError(t, err)
+	base, err := filepath.Abs(filepath.Join("testdata", "show"))
+	must.BeNoError(t, err)
+
+	var out bytes.Buffer
+	err = cfg.Show(&out, "all")
+	must.BeNoError(t, err)
+	must.BeEqual(t, strings.Replace(`group: all
+  file: nedward.json
+  description: Everything
+  env:
+    REGION=test (group all, nedward.json)
+    API_KEY=<from_secret: api_key> (group all, nedward.json)
+  children: web, backend
+
+service: web
+  via: all
+  file: nedward.json
+  path: BASE/web
+  commands:
+    build: make
+    launch: ./web
+  launch checks:
+    ports: 8080
+  env:
+    PORT=8080 (service, nedward.json)
+    LOG_LEVEL=debug (service, nedward.json)
+    DB_PASS=<from_command: pass show db> (service, nedward.json)
+    REGION=test (group all, nedward.json)
+    API_KEY=<from_secret: api_key> (group all, nedward.json)
+  env files: .env
+
+group: backend
+  file: backend/nedward.yaml
+  env:
+    PORT=9000 (group backend, backend/nedward.yaml)
+    REGION=test (group all, nedward.json)
+    API_KEY=<from_secret: api_key> (group all, nedward.json)
+  env files: secrets.env
+  children: api
+
+service: api
+  via: all > backend
+  file: backend/nedward.yaml
+  path: BASE/api
+  commands:
+    launch: ./api
+  launch checks:
+    log text: started
+  env:
+    PORT=9090 (service, backend/nedward.yaml)
+    REGION=test (group all, nedward.json)
+    API_KEY=<from_secret: api_key> (group all, nedward.json)
+    LOG_LEVEL=info (global, nedward.json)
+  env files: backend/api.env, secrets.env, .env
+`, "BASE", base, -1), out.String())
+
+	out.Reset()
+	err = cfg.Show(&out, "missing")
+	must.BeEqualErrors(t, errors.New("Service or group not found: missing"), err)
+}
+
+func TestVars(t *testing.T) {
+	os.Setenv(VarEnvPrefix+"api_port", "9090")
+	defer os.Unsetenv(VarEnvPrefix + "api_port")
+
+	cfg, err := LoadConfigWithOptions(filepath.Join("testdata", "vars", "nedward.yaml"), "", nil, LoadOptions{
+		Vars: map[string]string{"region": "us"},
+	})
+	must.BeNoError(t, err)
+
+	api := cfg.ServiceMap["api"]
+	must.BeEqual(t, filepath.Join("testdata", "vars", "api-dev"), *api.Path)
+	must.BeEqual(t, "make dev", api.Commands.Build)
+	must.BeEqual(t, "./api --port 9090 --home ${HOME}", api.Commands.Launch)
+	must.BeEqual(t, "listening on 9090", api.LaunchChecks.LogText)
+	must.BeEqual(t, "http://localhost:9090/health", api.Warmup.URL)
+	must.BeEqual(t, []string{"API_URL=http://localhost:9090"}, api.Env)
+	must.BeEqual(t, []string{"STAGE=dev"}, api.GlobalEnv)
+	must.BeEqual(t, `{"include":["src/dev"]}`, string(api.WatchJSON))
+	must.BeEqual(t, []string{"REGION=us"}, cfg.GroupMap["all"].Env)
+
+	// Definitions are left as they were in the file, so they may be saved
+	must.BeEqual(t, "make ${env}", cfg.Services[0].Commands.Build)
+	must.BeEqual(t, []string{"API_URL=${api_url}"}, cfg.Services[0].Env)
+}
+
+func TestValidateVars(t *testing.T) {
+	problems, err := Validate(filepath.Join("testdata", "vars", "nedward.yaml"), LoadOptions{})
+	must.BeNoError(t, err)
+
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.String())
+	}
+	must.BeEqual(t, []string{
+		"testdata/vars/nedward.yaml:26: services[1].commands.launch: unresolved reference ${regoin}, did you mean ${region}?",
+	}, got)
+}
+
+func TestProfiles(t *testing.T) {
+	configPath := filepath.Join("testdata", "profiles", "nedward.json")
+
+	cfg, err := LoadConfigWithOptions(configPath, "", nil, LoadOptions{Profile: "debug"})
+	must.BeNoError(t, err)
+	api := cfg.ServiceMap["api"]
+	must.BeEqual(t, "debug", api.Profile)
+	must.BeEqual(t, services.ServiceConfigCommands{Build: "make", Launch: "dlv exec ./api -- --port 8080"}, api.Commands)
+	must.BeEqual(t, []string{"LOG_LEVEL=info", "MOCKS=false", "LOG_LEVEL=debug"}, api.Env)
+	must.BeEqual(t, []services.EnvSecret{{Name: "DEBUG_TOKEN", FromCommand: "cat token"}}, api.SecretEnv)
+	must.BeEqual(t, &services.LaunchChecks{LogText: "API server listening"}, api.LaunchChecks)
+	must.BeEqual(t, `{"include":["src","debug"]}`, string(api.WatchJSON))
+	// Services that do not define the profile are unchanged, but record that it was selected
+	must.BeEqual(t, "debug", cfg.ServiceMap["web"].Profile)
+	must.BeEqual(t, "./web", cfg.ServiceMap["web"].Commands.Launch)
+	must.BeEqual(t, []string{"REGION=local"}, cfg.GroupMap["all"].Env)
+
+	cfg, err = LoadConfigWithOptions(configPath, "", nil, LoadOptions{Profile: "prod-data"})
+	must.BeNoError(t, err)
+	must.BeEqual(t, []string{"REGION=local", "REGION=prod"}, cfg.GroupMap["all"].Env)
+	must.BeEqual(t, "./api", cfg.ServiceMap["api"].Commands.Launch)
+	must.BeEqual(t, []string{"LOG_LEVEL=info", "MOCKS=false"}, cfg.ServiceMap["api"].Env)
+
+	cfg, err = LoadConfigWithOptions(configPath, "", nil, LoadOptions{})
+	must.BeNoError(t, err)
+	must.BeEqual(t, "", cfg.ServiceMap["api"].Profile)
+	must.BeEqual(t, &services.LaunchChecks{Ports: []int{8080}}, cfg.ServiceMap["api"].LaunchChecks)
+
+	_, err = LoadConfigWithOptions(configPath, "", nil, LoadOptions{Profile: "prod"})
+	must.BeEqualErrors(t, errors.New("profile prod is not defined by any service or group"), err)
+}
+
+func TestTransitiveImports(t *testing.T) {
+	dir := filepath.Join("testdata", "importtree")
+	cfg, err := LoadConfig(filepath.Join(dir, "nedward.json"), "", nil)
+	must.BeNoError(t, err)
+
+	sources := make(map[string]string)
+	for name, service := range cfg.ServiceMap {
+		sources[name] = service.SourceFile
+	}
+	must.BeEqual(t, map[string]string{
+		"api": filepath.Join(dir, "services", "api", "nedward.json"),
+		"web": filepath.Join(dir, "services", "web", "nedward.json"),
+		"db":  filepath.Join(dir, "services", "api", "..", "..", "shared", "nedward.json"),
+	}, sources)
+	must.BeEqual(t, []string{"db"}, cfg.GroupMap["backend"].ChildOrder)
+	// Variables set in a file take precedence over those from the files it imports
+	must.BeEqual(t, "./api --port 8080 --db 5432", cfg.ServiceMap["api"].Commands.Launch)
+
+	_, err = LoadConfig(filepath.Join("testdata", "importcycle", "nedward.json"), "", nil)
+	must.BeEqualErrors(t, errors.New("import cycle: testdata/importcycle/a.json -> testdata/importcycle/b.json -> testdata/importcycle/a.json"), err)
+
+	_, err = LoadConfig(filepath.Join("testdata", "importnone.json"), "", nil)
+	must.BeEqualErrors(t, errors.New("no files match import nothing/*/nedward.json"), err)
+}
+
+func TestNamespacedImports(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join("testdata", "namespaces", "nedward.json"), "", nil)
+	must.BeNoError(t, err)
+
+	var names []string
+	for name := range cfg.ServiceMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	must.BeEqual(t, []string{"billing/api", "billing/ledger", "payments/api", "web"}, names)
+	must.BeEqual(t, []string{"payments/payments-api"}, cfg.ServiceMap["payments/api"].Aliases)
+	// References within an imported file are rewritten, while others are left as they were
+	must.BeEqual(t, []string{"payments/api", "web"}, cfg.GroupMap["payments/backend"].ChildOrder)
+	must.BeEqual(t, []string{"billing/api", "billing/ledger"}, cfg.GroupMap["billing/backend"].ChildOrder)
+	// Unqualified names are resolved where they are unambiguous
+	must.BeEqual(t, []string{"web", "payments/backend", "billing/ledger"}, cfg.GroupMap["all"].ChildOrder)
+
+	var tests = []struct {
+		name     string
+		expected string
+		err      error
+	}{
+		{name: "payments/api", expected: "payments/api"},
+		{name: "ledger", expected: "billing/ledger"},
+		{name: "payments-api", expected: "payments/api"},
+		{name: "web", expected: "web"},
+		{name: "api", err: errors.New("api is ambiguous, could be any of: billing/api, payments/api")},
+		{name: "backend", err: errors.New("backend is ambiguous, could be any of: billing/backend, payments/backend")},
+		{name: "missing"},
+	}
+	for _, test := range tests {
+		sg, err := services.FindServiceOrGroup(test.name, cfg.ServiceMap, cfg.GroupMap)
+		must.BeEqualErrors(t, test.err, err, test.name)
+		if test.expected == "" {
+			must.BeEqual(t, nil, sg, test.name)
+			continue
+		}
+		must.BeEqual(t, test.expected, sg.GetName(), test.name)
+	}
+}
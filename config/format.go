@@ -0,0 +1,515 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	toml "github.com/pelletier/go-toml"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FileNames lists the file names recognized as Nedward config files, in order of preference
+var FileNames = []string{
+	"nedward.json",
+	"nedward.yaml",
+	"nedward.yml",
+	"nedward.toml",
+}
+
+// format identifies the syntax of a config file
+type format int
+
+const (
+	formatJSON format = iota
+	formatYAML
+	formatTOML
+)
+
+// formatForPath returns the format of a config file based on its extension.
+// Files without a recognized extension are assumed to be JSON.
+func formatForPath(path string) format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	}
+	return formatJSON
+}
+
+var (
+	yamlErrorExpr = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+	tomlErrorExpr = regexp.MustCompile(`^\((\d+), (\d+)\): (.*)$`)
+	yamlKeyExpr   = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s"'#\[\]{}][^#]*?)\s*:(?:\s+(.*))?$`)
+)
+
+// positions maps the path to each value in a config file to the line on which it was defined.
+// Paths take the form "services[0].commands.launch", with the document itself at the empty path.
+type positions map[string]int
+
+// line returns the line on which the value at path was defined. If the value itself could not be
+// located, the line of the nearest enclosing value is returned.
+func (p positions) line(path string) int {
+	for {
+		if line, ok := p[path]; ok {
+			return line
+		}
+		if path == "" {
+			return 0
+		}
+		if i := strings.LastIndexAny(path, ".["); i >= 0 {
+			path = path[:i]
+		} else {
+			path = ""
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// parseError describes an error in the content of a config file, with its location
+type parseError struct {
+	line, char int
+	err        error
+}
+
+func (e *parseError) Error() string {
+	switch {
+	case e.line > 0 && e.char > 0:
+		return fmt.Sprintf("could not parse config file (line %v, char %v): %v", e.line, e.char, e.err)
+	case e.line > 0:
+		return fmt.Sprintf("could not parse config file (line %v): %v", e.line, e.err)
+	}
+	return fmt.Sprintf("could not parse config file: %v", e.err)
+}
+
+// decodeConfig parses the contents of a config file in the given format into config.
+// Errors in the content are reported with the line at which they occurred.
+func decodeConfig(data []byte, f format, config *Config) (positions, error) {
+	content, lines, err := parseDocument(data, f)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = json.Unmarshal(content, config)
+	if err != nil {
+		return nil, errors.WithStack(&parseError{line: lines.line(errorPath(content)), err: err})
+	}
+	return lines, nil
+}
+
+// parseDocument parses the contents of a config file in the given format, returning the
+// equivalent JSON document, and the line on which each value was defined.
+func parseDocument(data []byte, f format) ([]byte, positions, error) {
+	switch f {
+	case formatYAML:
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			if match := yamlErrorExpr.FindStringSubmatch(err.Error()); match != nil {
+				line, _ := strconv.Atoi(match[1])
+				return nil, nil, &parseError{line: line, err: errors.New(match[2])}
+			}
+			return nil, nil, &parseError{err: err}
+		}
+		content, err := json.Marshal(jsonCompatible(doc))
+		if err != nil {
+			return nil, nil, &parseError{err: err}
+		}
+		return content, yamlPositions(data), nil
+	case formatTOML:
+		tree, err := toml.Load(string(data))
+		if err != nil {
+			if match := tomlErrorExpr.FindStringSubmatch(err.Error()); match != nil {
+				line, _ := strconv.Atoi(match[1])
+				char, _ := strconv.Atoi(match[2])
+				return nil, nil, &parseError{line: line, char: char, err: errors.New(match[3])}
+			}
+			return nil, nil, &parseError{err: err}
+		}
+		content, err := json.Marshal(jsonCompatible(tree.ToMap()))
+		if err != nil {
+			return nil, nil, &parseError{err: err}
+		}
+		return content, tomlPositions(tree), nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		if syntax, ok := err.(*json.SyntaxError); ok && syntax.Offset != 0 {
+			start := strings.LastIndex(string(data[:syntax.Offset]), "\n") + 1
+			line, pos := strings.Count(string(data[:start]), "\n")+1, int(syntax.Offset)-start-1
+			return nil, nil, &parseError{line: line, char: pos, err: err}
+		}
+		return nil, nil, &parseError{err: err}
+	}
+	return data, jsonPositions(data), nil
+}
+
+// errorPath identifies the path to the value in a JSON document that prevents it being
+// unmarshaled into a Config, by unmarshaling each top-level value, and each element of
+// top-level arrays, in isolation.
+func errorPath(content []byte) string {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return ""
+	}
+	var keys []string
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := key
+		err := unmarshalConfigValue(key, doc[key])
+		if err == nil {
+			continue
+		}
+		if items, ok := doc[key].([]interface{}); ok {
+			for i, item := range items {
+				if itemErr := unmarshalConfigValue(key, []interface{}{item}); itemErr != nil {
+					path, err = fmt.Sprintf("%v[%v]", key, i), itemErr
+					break
+				}
+			}
+		}
+		if typeErr, ok := errors.Cause(err).(*json.UnmarshalTypeError); ok {
+			path += fieldPath(key, typeErr.Field)
+		}
+		return path
+	}
+	return ""
+}
+
+// fieldPath converts the field of an UnmarshalTypeError for a value unmarshaled by
+// unmarshalConfigValue, such as "groups.0.children.1", into a path relative to that
+// value, such as ".children[1]".
+func fieldPath(key, field string) string {
+	segments := strings.Split(strings.TrimPrefix(field, "."), ".")
+	if len(segments) > 0 && segments[0] == key {
+		segments = segments[1:]
+		if len(segments) > 0 && segments[0] == "0" {
+			segments = segments[1:]
+		}
+	}
+	var path string
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(segment); err == nil {
+			path += "[" + segment + "]"
+		} else {
+			path += "." + segment
+		}
+	}
+	return path
+}
+
+func unmarshalConfigValue(key string, value interface{}) error {
+	content, err := json.Marshal(map[string]interface{}{key: value})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var config Config
+	return json.Unmarshal(content, &config)
+}
+
+// jsonCompatible converts a document parsed from YAML or TOML into a form that can be
+// marshaled as JSON, with string keys for all maps.
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = jsonCompatible(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = jsonCompatible(item)
+		}
+		return result
+	}
+	return value
+}
+
+// jsonPositions returns the line on which each value in a JSON document is defined.
+// Members of an object are located by their key.
+func jsonPositions(data []byte) positions {
+	lines := positions{"": 1}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	lineAt := func() int {
+		return bytes.Count(data[:decoder.InputOffset()], []byte("\n")) + 1
+	}
+
+	var readValue func(path string) error
+	readValue = func(path string) error {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if _, located := lines[path]; !located {
+			lines[path] = lineAt()
+		}
+		switch token {
+		case json.Delim('{'):
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				keyPath := joinPath(path, fmt.Sprint(key))
+				lines[keyPath] = lineAt()
+				if err := readValue(keyPath); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+		case json.Delim('['):
+			for i := 0; decoder.More(); i++ {
+				if err := readValue(fmt.Sprintf("%v[%v]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+		}
+		return err
+	}
+	_ = readValue("")
+	return lines
+}
+
+// tomlPositions returns the line on which each value in a TOML document is defined.
+// Elements of arrays of tables are located by their table header, elements of other arrays
+// are not located individually.
+func tomlPositions(tree *toml.Tree) positions {
+	lines := positions{"": 1}
+	var walk func(path string, tree *toml.Tree)
+	walk = func(path string, tree *toml.Tree) {
+		for _, key := range tree.Keys() {
+			keyPath := joinPath(path, key)
+			lines[keyPath] = tree.GetPositionPath([]string{key}).Line
+			switch value := tree.GetPath([]string{key}).(type) {
+			case *toml.Tree:
+				walk(keyPath, value)
+			case []*toml.Tree:
+				for i, item := range value {
+					itemPath := fmt.Sprintf("%v[%v]", keyPath, i)
+					lines[itemPath] = item.Position().Line
+					walk(itemPath, item)
+				}
+			}
+		}
+	}
+	walk("", tree)
+	return lines
+}
+
+// yamlPositions returns the line on which each value in a YAML document is defined, based on
+// the indentation of each line. Only block collections are indexed, values within flow
+// collections such as [a, b] are attributed to the line of the collection.
+func yamlPositions(data []byte) positions {
+	type collection struct {
+		indent   int
+		path     string
+		sequence bool
+		items    int
+	}
+
+	lines := positions{"": 1}
+	var (
+		stack []*collection
+		// Path of the most recent key or sequence item, which will contain any nested collection
+		parent string
+		// Indent of the key introducing a block scalar being skipped, or -1
+		blockIndent = -1
+	)
+	for number, text := range strings.Split(string(data), "\n") {
+		content := strings.TrimLeft(text, " ")
+		indent := len(text) - len(content)
+		content = strings.TrimRight(content, " \t\r")
+		if blockIndent >= 0 {
+			if content == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+		if content == "" || content == "---" || content == "..." || strings.HasPrefix(content, "#") || strings.HasPrefix(content, "%") {
+			continue
+		}
+
+		for content != "" {
+			item := content == "-" || strings.HasPrefix(content, "- ")
+			for len(stack) > 0 {
+				top := stack[len(stack)-1]
+				if top.indent > indent || (top.indent == indent && top.sequence && !item) {
+					stack = stack[:len(stack)-1]
+					continue
+				}
+				break
+			}
+			var top *collection
+			if len(stack) > 0 {
+				top = stack[len(stack)-1]
+			}
+
+			if item {
+				if top == nil || !top.sequence || top.indent != indent {
+					top = &collection{indent: indent, path: parent, sequence: true}
+					stack = append(stack, top)
+				}
+				parent = fmt.Sprintf("%v[%v]", top.path, top.items)
+				top.items++
+				lines[parent] = number + 1
+				rest := strings.TrimLeft(content[1:], " ")
+				indent += len(content) - len(rest)
+				content = rest
+				continue
+			}
+
+			match := yamlKeyExpr.FindStringSubmatch(content)
+			if match == nil {
+				break
+			}
+			if top == nil || top.sequence || top.indent < indent {
+				top = &collection{indent: indent, path: parent}
+				stack = append(stack, top)
+			}
+			key := match[1]
+			if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') {
+				key = key[1 : len(key)-1]
+			}
+			parent = joinPath(top.path, key)
+			lines[parent] = number + 1
+			if strings.HasPrefix(match[2], "|") || strings.HasPrefix(match[2], ">") {
+				blockIndent = indent
+			}
+			break
+		}
+	}
+	return lines
+}
+
+// encodeConfig converts a config marshaled as JSON into the given format
+func encodeConfig(content []byte, f format) ([]byte, error) {
+	switch f {
+	case formatYAML:
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		doc, err := readOrdered(decoder)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		out, err := yaml.Marshal(doc)
+		return out, errors.WithStack(err)
+	case formatTOML:
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		tree, err := toml.TreeFromMap(tomlCompatible(doc).(map[string]interface{}))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		out, err := tree.ToTomlString()
+		return []byte(out), errors.WithStack(err)
+	}
+	return content, nil
+}
+
+// readOrdered reads a JSON value from decoder, preserving the order of keys in objects
+func readOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		var result yaml.MapSlice
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, yaml.MapItem{Key: key, Value: value})
+		}
+		_, err = decoder.Token()
+		return result, err
+	case json.Delim('['):
+		result := []interface{}{}
+		for decoder.More() {
+			value, err := readOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		_, err = decoder.Token()
+		return result, err
+	}
+	return number(token), nil
+}
+
+// tomlCompatible converts a decoded JSON document into values supported by TOML,
+// omitting nulls, which TOML cannot represent.
+func tomlCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if item != nil {
+				result[key] = tomlCompatible(item)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if item != nil {
+				result = append(result, tomlCompatible(item))
+			}
+		}
+		return result
+	}
+	return number(value)
+}
+
+// number converts a json.Number into an int64 or float64, leaving other values unchanged
+func number(value interface{}) interface{} {
+	n, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
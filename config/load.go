@@ -18,18 +18,20 @@ func GetConfigPathFromWorkingDirectory() (string, error) {
 	return GetConfigPath(wd), nil
 }
 
-// GetConfigPath identifies the location of nedward.json, if any exists
+// GetConfigPath identifies the location of a config file, if any exists.
+// The Nedward config dir is searched first, followed by the working directory and its parents.
+// Within each directory, config files are chosen in the order listed in FileNames.
 func GetConfigPath(wd string) string {
 	var pathOptions []string
 
 	// Config file in Nedward Config dir
-	pathOptions = append(pathOptions, filepath.Join(home.NedwardConfig.Dir, "nedward.json"))
+	pathOptions = append(pathOptions, configPathOptions(home.NedwardConfig.Dir)...)
 
 	// Config file in current working directory
-	pathOptions = append(pathOptions, filepath.Join(wd, "nedward.json"))
+	pathOptions = append(pathOptions, configPathOptions(wd)...)
 	for path.Dir(wd) != wd {
 		wd = path.Dir(wd)
-		pathOptions = append(pathOptions, filepath.Join(wd, "nedward.json"))
+		pathOptions = append(pathOptions, configPathOptions(wd)...)
 	}
 
 	for _, path := range pathOptions {
@@ -47,3 +49,11 @@ func GetConfigPath(wd string) string {
 
 	return ""
 }
+
+func configPathOptions(dir string) []string {
+	var options []string
+	for _, name := range FileNames {
+		options = append(options, filepath.Join(dir, name))
+	}
+	return options
+}
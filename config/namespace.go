@@ -0,0 +1,62 @@
+package config
+
+import (
+	"github.com/nedscode/nedward/services"
+)
+
+// withNamespace returns a copy of this config, with the names and aliases of its services and groups,
+// including those it imports, prefixed with namespace. Children of groups that refer to these services
+// and groups are updated to match, while other children are left as they were, so they may refer to
+// services and groups defined elsewhere.
+func (c Config) withNamespace(namespace string) Config {
+	var names = make(map[string]bool)
+	for _, list := range [][]services.ServiceConfig{c.Services, c.ImportedServices} {
+		for _, s := range list {
+			names[s.Name] = true
+		}
+	}
+	for _, list := range [][]GroupDef{c.Groups, c.ImportedGroups} {
+		for _, g := range list {
+			names[g.Name] = true
+		}
+	}
+
+	qualify := func(values []string, all bool) []string {
+		if values == nil {
+			return nil
+		}
+		var result = make([]string, len(values))
+		for i, value := range values {
+			result[i] = value
+			if all || names[value] {
+				result[i] = services.Qualify(namespace, value)
+			}
+		}
+		return result
+	}
+	namespaceServices := func(list []services.ServiceConfig) []services.ServiceConfig {
+		var result []services.ServiceConfig
+		for _, s := range list {
+			s.Name = services.Qualify(namespace, s.Name)
+			s.Aliases = qualify(s.Aliases, true)
+			result = append(result, s)
+		}
+		return result
+	}
+	namespaceGroups := func(list []GroupDef) []GroupDef {
+		var result []GroupDef
+		for _, g := range list {
+			g.Name = services.Qualify(namespace, g.Name)
+			g.Aliases = qualify(g.Aliases, true)
+			g.Children = qualify(g.Children, false)
+			result = append(result, g)
+		}
+		return result
+	}
+
+	c.Services = namespaceServices(c.Services)
+	c.ImportedServices = namespaceServices(c.ImportedServices)
+	c.Groups = namespaceGroups(c.Groups)
+	c.ImportedGroups = namespaceGroups(c.ImportedGroups)
+	return c
+}
@@ -0,0 +1,342 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+)
+
+// SchemaURI identifies the version of JSON Schema used by Schema
+const SchemaURI = "http://json-schema.org/draft-07/schema#"
+
+// Platforms lists the values of GOOS that may be specified as the platform for a service
+var Platforms = []string{
+	"aix",
+	"android",
+	"darwin",
+	"dragonfly",
+	"freebsd",
+	"illumos",
+	"ios",
+	"js",
+	"linux",
+	"netbsd",
+	"openbsd",
+	"plan9",
+	"solaris",
+	"wasip1",
+	"windows",
+}
+
+// Schema returns a JSON Schema describing the structure of a config file, which may be used
+// by editors to validate and autocomplete config files.
+func Schema() map[string]interface{} {
+	schema := configSchema()
+	schema["$schema"] = SchemaURI
+	schema["title"] = "Nedward config"
+	return schema
+}
+
+// configSchema builds a schema for the Config type, with additional constraints on fields
+// that cannot be expressed by their Go types alone.
+func configSchema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(Config{}))
+	properties := schema["properties"].(map[string]interface{})
+
+	service := itemSchema(properties["services"])
+	serviceProperties := service["properties"].(map[string]interface{})
+	service["required"] = []string{"name"}
+	watch := typeSchema(reflect.TypeOf(services.ServiceWatch{}))
+	watch["type"] = []string{"string", "object"}
+	serviceProperties["watch"] = watch
+	serviceProperties["type"] = map[string]interface{}{
+		"type": "string",
+		"enum": []string{"", services.ServiceTypeDocker},
+	}
+	serviceProperties["platform"] = map[string]interface{}{
+		"type": "string",
+		"enum": Platforms,
+	}
+	legacyProperties := typeSchema(reflect.TypeOf(services.ServiceConfigProperties{}))
+	legacyProperties["deprecated"] = true
+	serviceProperties["log_properties"] = legacyProperties
+
+	group := itemSchema(properties["groups"])
+	group["required"] = []string{"name"}
+
+	imports := itemSchema(properties["imports"])
+	imports["type"] = []string{"string", "object"}
+	imports["required"] = []string{"path"}
+
+	serviceProfile := serviceProperties["profiles"].(map[string]interface{})["additionalProperties"].(map[string]interface{})
+	serviceProfileProperties := serviceProfile["properties"].(map[string]interface{})
+	serviceProfileProperties["watch"] = watch
+
+	for _, schema := range []map[string]interface{}{serviceProperties, serviceProfileProperties} {
+		launchChecks := schema["launch_checks"].(map[string]interface{})
+		port := itemSchema(launchChecks["properties"].(map[string]interface{})["ports"])
+		port["minimum"] = 1
+		port["maximum"] = 65535
+	}
+
+	groupProperties := group["properties"].(map[string]interface{})
+	groupProfile := groupProperties["profiles"].(map[string]interface{})["additionalProperties"].(map[string]interface{})
+
+	for _, schema := range []map[string]interface{}{
+		properties,
+		serviceProperties,
+		serviceProfileProperties,
+		groupProperties,
+		groupProfile["properties"].(map[string]interface{}),
+	} {
+		envFile := schema["env_file"].(map[string]interface{})
+		envFile["type"] = []string{"string", "array"}
+		env := itemSchema(schema["env"])
+		secret := typeSchema(reflect.TypeOf(services.EnvSecret{}))
+		env["type"] = []string{"string", "object"}
+		env["properties"] = secret["properties"]
+		env["required"] = []string{"name"}
+		env["additionalProperties"] = false
+	}
+
+	return schema
+}
+
+func itemSchema(arraySchema interface{}) map[string]interface{} {
+	return arraySchema.(map[string]interface{})["items"].(map[string]interface{})
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// typeSchema returns a schema for values of type t, based on its JSON encoding
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t == rawMessageType {
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem()),
+		}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if field.PkgPath != "" || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = typeSchema(field.Type)
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// checkSchema checks a value decoded from JSON against a schema produced by typeSchema,
+// calling report with the path to each value that does not conform.
+func checkSchema(schema map[string]interface{}, value interface{}, path string, report func(path, message string)) {
+	if value == nil {
+		return
+	}
+	var expected []string
+	switch t := schema["type"].(type) {
+	case string:
+		expected = []string{t}
+	case []string:
+		expected = t
+	}
+	if len(expected) > 0 && !hasType(value, expected) {
+		var descriptions []string
+		for _, t := range expected {
+			descriptions = append(descriptions, describeType(t))
+		}
+		report(path, fmt.Sprintf("expected %v, found %v", strings.Join(descriptions, " or "), describeValue(value)))
+		return
+	}
+	if enum, ok := schema["enum"].([]string); ok {
+		s := value.(string)
+		for _, allowed := range enum {
+			if s == allowed {
+				return
+			}
+		}
+		if suggestion := closest(s, enum); suggestion != "" {
+			report(path, fmt.Sprintf("unknown value %q, did you mean %q?", s, suggestion))
+		} else {
+			report(path, fmt.Sprintf("unknown value %q, expected one of: %v", s, strings.Join(enum, ", ")))
+		}
+		return
+	}
+	if n, ok := value.(float64); ok {
+		if minimum, ok := schema["minimum"].(int); ok && n < float64(minimum) {
+			report(path, fmt.Sprintf("%v is less than the minimum of %v", n, minimum))
+		}
+		if maximum, ok := schema["maximum"].(int); ok && n > float64(maximum) {
+			report(path, fmt.Sprintf("%v is greater than the maximum of %v", n, maximum))
+		}
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				checkSchema(items, item, fmt.Sprintf("%v[%v]", path, i), report)
+			}
+		}
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		var keys []string
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := joinPath(path, key)
+			if property, ok := properties[key].(map[string]interface{}); ok {
+				checkSchema(property, v[key], keyPath, report)
+				continue
+			}
+			if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+				checkSchema(additional, v[key], keyPath, report)
+				continue
+			}
+			if schema["additionalProperties"] == false {
+				var known []string
+				for name := range properties {
+					known = append(known, name)
+				}
+				if suggestion := closest(key, known); suggestion != "" {
+					report(keyPath, fmt.Sprintf("unknown key %q, did you mean %q?", key, suggestion))
+				} else {
+					report(keyPath, fmt.Sprintf("unknown key %q", key))
+				}
+			}
+		}
+		if required, ok := schema["required"].([]string); ok {
+			for _, key := range required {
+				if _, ok := v[key]; !ok {
+					report(path, fmt.Sprintf("missing required key %q", key))
+				}
+			}
+		}
+	}
+}
+
+func hasType(value interface{}, expected []string) bool {
+	for _, t := range expected {
+		switch v := value.(type) {
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == float64(int64(v))) {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func describeType(schemaType string) string {
+	switch schemaType {
+	case "array", "object", "integer":
+		return "an " + schemaType
+	}
+	return "a " + schemaType
+}
+
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprint(value)
+}
+
+// closest returns the candidate nearest to s, if it is close enough to be a likely typo
+func closest(s string, candidates []string) string {
+	var best string
+	bestDistance := len(s)/3 + 2
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if distance := editDistance(strings.ToLower(s), strings.ToLower(candidate)); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
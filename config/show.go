@@ -0,0 +1,289 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+// Show writes the resolved config for the named services and groups to w, as it will be used once
+// imports have been loaded and paths and env combined. If no names are given, all groups and
+// services are shown.
+func (c *Config) Show(w io.Writer, names ...string) error {
+	if len(names) == 0 {
+		var groupNames, serviceNames []string
+		for name := range c.GroupMap {
+			groupNames = append(groupNames, name)
+		}
+		for name := range c.ServiceMap {
+			serviceNames = append(serviceNames, name)
+		}
+		sort.Strings(groupNames)
+		sort.Strings(serviceNames)
+		names = append(groupNames, serviceNames...)
+	}
+
+	for i, name := range names {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		sg, err := services.FindServiceOrGroup(name, c.ServiceMap, c.GroupMap)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		switch sg := sg.(type) {
+		case *services.ServiceGroupConfig:
+			c.showGroup(w, sg, nil)
+		case *services.ServiceConfig:
+			c.showService(w, sg, nil)
+		default:
+			return errors.Errorf("Service or group not found: %v", name)
+		}
+	}
+	return nil
+}
+
+func (c *Config) showGroup(w io.Writer, group *services.ServiceGroupConfig, parents []*services.ServiceGroupConfig) {
+	fmt.Fprintf(w, "group: %v\n", group.Name)
+	if def := c.groupDef(group.Name); def != nil {
+		fmt.Fprintf(w, "  file: %v\n", c.relativePath(def.SourceFile))
+		if _, ok := def.Profiles[c.options.Profile]; ok {
+			fmt.Fprintf(w, "  profile: %v\n", c.options.Profile)
+		}
+	}
+	if len(group.Aliases) > 0 {
+		fmt.Fprintf(w, "  aliases: %v\n", strings.Join(group.Aliases, ", "))
+	}
+	if group.Description != "" {
+		fmt.Fprintf(w, "  description: %v\n", group.Description)
+	}
+	chain := append(append([]*services.ServiceGroupConfig{}, parents...), group)
+	showEnv(w, c.groupEnv(nil, chain))
+	c.showEnvFiles(w, c.groupEnvFiles(nil, chain))
+	fmt.Fprintf(w, "  children: %v\n", strings.Join(group.ChildOrder, ", "))
+
+	for _, child := range group.ChildOrder {
+		for _, service := range group.Services {
+			if service.Name == child {
+				fmt.Fprintln(w)
+				c.showService(w, service, chain)
+			}
+		}
+		for _, childGroup := range group.Groups {
+			if childGroup.Name == child {
+				fmt.Fprintln(w)
+				c.showGroup(w, childGroup, chain)
+			}
+		}
+	}
+}
+
+func (c *Config) showService(w io.Writer, service *services.ServiceConfig, groups []*services.ServiceGroupConfig) {
+	fmt.Fprintf(w, "service: %v\n", service.Name)
+	if len(groups) > 0 {
+		var names []string
+		for _, group := range groups {
+			names = append(names, group.Name)
+		}
+		fmt.Fprintf(w, "  via: %v\n", strings.Join(names, " > "))
+	}
+	fmt.Fprintf(w, "  file: %v\n", c.relativePath(service.SourceFile))
+	if _, ok := service.Profiles[service.Profile]; ok {
+		fmt.Fprintf(w, "  profile: %v\n", service.Profile)
+	}
+	if len(service.Aliases) > 0 {
+		fmt.Fprintf(w, "  aliases: %v\n", strings.Join(service.Aliases, ", "))
+	}
+	if service.Description != "" {
+		fmt.Fprintf(w, "  description: %v\n", service.Description)
+	}
+	fmt.Fprintf(w, "  path: %v\n", c.servicePath(service))
+	if service.Platform != "" {
+		fmt.Fprintf(w, "  platform: %v\n", service.Platform)
+	}
+	if service.IsDocker() {
+		fmt.Fprintf(w, "  docker image: %v\n", service.Docker.Image)
+		fmt.Fprintf(w, "  docker container: %v\n", service.ContainerName())
+	}
+
+	commands := []struct{ name, command string }{
+		{"install", service.Commands.Install},
+		{"update", service.Commands.Update},
+		{"build", service.Commands.Build},
+		{"launch", service.Commands.Launch},
+		{"stop", service.Commands.Stop},
+	}
+	fmt.Fprintln(w, "  commands:")
+	for _, command := range commands {
+		if command.command != "" {
+			fmt.Fprintf(w, "    %v: %v\n", command.name, command.command)
+		}
+	}
+
+	if checks := service.LaunchChecks; checks != nil {
+		fmt.Fprintln(w, "  launch checks:")
+		if checks.LogText != "" {
+			fmt.Fprintf(w, "    log text: %v\n", checks.LogText)
+		}
+		if len(checks.Ports) > 0 {
+			var ports []string
+			for _, port := range checks.Ports {
+				ports = append(ports, fmt.Sprint(port))
+			}
+			fmt.Fprintf(w, "    ports: %v\n", strings.Join(ports, ", "))
+		}
+		if checks.Wait > 0 {
+			fmt.Fprintf(w, "    wait: %vms\n", checks.Wait)
+		}
+	}
+
+	showEnv(w, c.serviceEnv(service, groups))
+	c.showEnvFiles(w, append(c.groupEnvFiles(service.EnvFile, groups), service.GlobalEnvFile...))
+}
+
+func (c *Config) showEnvFiles(w io.Writer, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	var files []string
+	for _, path := range paths {
+		files = append(files, c.relativePath(path))
+	}
+	fmt.Fprintf(w, "  env files: %v\n", strings.Join(files, ", "))
+}
+
+// envVar is an environment variable with a description of where its value was set
+type envVar struct {
+	key, value, source string
+}
+
+func showEnv(w io.Writer, env []envVar) {
+	if len(env) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "  env:")
+	for _, v := range env {
+		fmt.Fprintf(w, "    %v=%v (%v)\n", v.key, v.value, v.source)
+	}
+}
+
+// serviceEnv returns the env for a service started via the given chain of groups, in order of
+// precedence, as used by services.ServiceCommand: service env, followed by group env from the
+// innermost group to the outermost, then global env.
+// Variables hidden by a definition of the same key with higher precedence are omitted.
+func (c *Config) serviceEnv(service *services.ServiceConfig, groups []*services.ServiceGroupConfig) []envVar {
+	entries := withSecrets(service.Env, service.SecretEnv)
+	env := appendEnv(nil, entries, fmt.Sprintf("service, %v", c.relativePath(service.SourceFile)))
+	env = c.groupEnv(env, groups)
+	entries = withSecrets(interpolateAll(c.Env, c.vars), interpolateSecrets(c.SecretEnv, c.vars))
+	return appendEnv(env, entries, fmt.Sprintf("global, %v", c.relativePath(c.FilePath)))
+}
+
+// groupEnv appends the env set by a chain of groups to env, from the innermost group to the outermost
+func (c *Config) groupEnv(env []envVar, groups []*services.ServiceGroupConfig) []envVar {
+	for i := len(groups) - 1; i >= 0; i-- {
+		group := groups[i]
+		source := "group " + group.Name
+		if def := c.groupDef(group.Name); def != nil {
+			source = fmt.Sprintf("group %v, %v", group.Name, c.relativePath(def.SourceFile))
+		}
+		env = appendEnv(env, withSecrets(group.Env, group.SecretEnv), source)
+	}
+	return env
+}
+
+// groupEnvFiles appends the env files set by a chain of groups to files, from the innermost
+// group to the outermost
+func (c *Config) groupEnvFiles(files []string, groups []*services.ServiceGroupConfig) []string {
+	files = append([]string{}, files...)
+	for i := len(groups) - 1; i >= 0; i-- {
+		files = append(files, groups[i].EnvFile...)
+	}
+	return files
+}
+
+// withSecrets appends entries for secrets to env, describing where their values come from
+// rather than resolving them. Secrets are appended last, as they take precedence over other
+// variables from the same source.
+func withSecrets(env []string, secretEnv []services.EnvSecret) []string {
+	env = append([]string{}, env...)
+	for _, secret := range secretEnv {
+		env = append(env, fmt.Sprintf("%v=<%v>", secret.Name, secret.Describe()))
+	}
+	return env
+}
+
+// appendEnv appends the entries from a single source to env, omitting keys that are already
+// defined by a source with higher precedence. Within entries, later definitions take precedence.
+func appendEnv(env []envVar, entries []string, source string) []envVar {
+	existing := len(env)
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		v := envVar{key: parts[0], source: source}
+		if len(parts) > 1 {
+			v.value = parts[1]
+		}
+		defined := -1
+		for i, e := range env {
+			if e.key == v.key {
+				defined = i
+				break
+			}
+		}
+		switch {
+		case defined < 0:
+			env = append(env, v)
+		case defined >= existing:
+			env[defined] = v
+		}
+	}
+	return env
+}
+
+// servicePath returns the absolute path in which commands for a service will be run. As when
+// starting a service, env variables are expanded and relative paths resolved against the
+// working directory.
+func (c *Config) servicePath(service *services.ServiceConfig) string {
+	path := "."
+	if service.Path != nil {
+		path = os.ExpandEnv(*service.Path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// relativePath returns path relative to the directory containing the config file, where possible
+func (c *Config) relativePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	base, err := filepath.Abs(c.workingDir)
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(base, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return abs
+}
+
+// groupDef returns the definition of a group as it appeared in its config file
+func (c *Config) groupDef(name string) *GroupDef {
+	for _, list := range [][]GroupDef{c.Groups, c.ImportedGroups} {
+		for i := range list {
+			if list[i].Name == name {
+				return &list[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+// Problem describes an issue found when validating a config file
+type Problem struct {
+	// Path to the file containing the problem
+	File string
+	// Line on which the problem was found, or 0 if it applies to the file as a whole
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%v:%v: %v", p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%v: %v", p.File, p.Message)
+}
+
+// Validate checks the config file at filePath, and all the files it imports, returning any
+// problems found. The config is only loaded in full if no problems are found in the individual
+// files, so problems such as duplicate names are reported once others have been resolved.
+// Variables in options are treated as defined when checking references to config variables.
+func Validate(filePath string, options LoadOptions) ([]Problem, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	v := &validator{
+		visited:  make(map[string]bool),
+		services: make(map[string]*services.ServiceConfig),
+		vars:     make(map[string]bool),
+		defined:  make(map[string]bool),
+	}
+	for name := range options.Vars {
+		v.vars[name] = true
+		v.defined[name] = true
+	}
+	v.validateFile(filePath)
+	v.checkGroups()
+	v.checkReferences()
+
+	if len(v.problems) == 0 {
+		if _, err := LoadConfigWithOptions(filePath, "", nil, options); err != nil {
+			v.problems = append(v.problems, Problem{File: filePath, Message: err.Error()})
+		}
+	}
+	return v.problems, nil
+}
+
+type validator struct {
+	problems []Problem
+	visited  map[string]bool
+	// Services from all files, by name and alias
+	services map[string]*services.ServiceConfig
+	groups   []validatedGroup
+	// Names of config variables and env variables defined in any file
+	vars       map[string]bool
+	defined    map[string]bool
+	references []varReference
+}
+
+// varReference records a reference to a config variable for checking once all files have been loaded
+type varReference struct {
+	file  string
+	path  string
+	lines positions
+	name  string
+}
+
+// validatedGroup records a group for checking once services have been loaded from all files
+type validatedGroup struct {
+	file  string
+	path  string
+	lines positions
+	group GroupDef
+}
+
+func (v *validator) validateFile(filePath string) {
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		if v.visited[absPath] {
+			return
+		}
+		v.visited[absPath] = true
+	}
+
+	start := len(v.problems)
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		v.problems = append(v.problems, Problem{File: filePath, Message: err.Error()})
+		return
+	}
+	content, lines, err := parseDocument(data, formatForPath(filePath))
+	if err != nil {
+		parseErr := err.(*parseError)
+		v.problems = append(v.problems, Problem{File: filePath, Line: parseErr.line, Message: parseErr.err.Error()})
+		return
+	}
+	report := func(path, message string) {
+		if path != "" {
+			message = path + ": " + message
+		}
+		v.problems = append(v.problems, Problem{File: filePath, Line: lines.line(path), Message: message})
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		report("", err.Error())
+		return
+	}
+	checkSchema(configSchema(), doc, "", report)
+	top, ok := doc.(map[string]interface{})
+	if !ok {
+		return
+	}
+	v.collectReferences(filePath, lines, top)
+
+	items, _ := top["services"].([]interface{})
+	for i, item := range items {
+		var service services.ServiceConfig
+		if err := remarshal(item, &service); err != nil {
+			if len(v.problems) == start {
+				report(fmt.Sprintf("services[%v]", i), err.Error())
+			}
+			continue
+		}
+		v.services[service.Name] = &service
+		for _, alias := range service.Aliases {
+			v.services[alias] = &service
+		}
+	}
+
+	items, _ = top["groups"].([]interface{})
+	for i, item := range items {
+		var group GroupDef
+		if err := remarshal(item, &group); err == nil {
+			v.groups = append(v.groups, validatedGroup{
+				file:  filePath,
+				path:  fmt.Sprintf("groups[%v]", i),
+				lines: lines,
+				group: group,
+			})
+		}
+	}
+
+	var imports []string
+	items, _ = top["imports"].([]interface{})
+	for i, item := range items {
+		importPath, ok := item.(string)
+		if object, isObject := item.(map[string]interface{}); isObject {
+			importPath, ok = object["path"].(string)
+			item = importPath
+		}
+		if !ok {
+			continue
+		}
+		if !filepath.IsAbs(importPath) {
+			importPath = filepath.Join(filepath.Dir(filePath), importPath)
+		}
+		if isGlob(importPath) {
+			matches, err := filepath.Glob(importPath)
+			if err != nil {
+				report(fmt.Sprintf("imports[%v]", i), fmt.Sprintf("invalid pattern %v: %v", item, err))
+				continue
+			}
+			matched := false
+			for _, match := range matches {
+				if !sameFile(match, filePath) {
+					imports = append(imports, match)
+					matched = true
+				}
+			}
+			if !matched {
+				report(fmt.Sprintf("imports[%v]", i), fmt.Sprintf("no files match import %v", item))
+			}
+			continue
+		}
+		if _, err := os.Stat(importPath); err != nil {
+			reason := err
+			if pathErr, ok := err.(*os.PathError); ok {
+				reason = pathErr.Err
+			}
+			report(fmt.Sprintf("imports[%v]", i), fmt.Sprintf("could not import %v: %v", importPath, reason))
+			continue
+		}
+		imports = append(imports, importPath)
+	}
+
+	problems := v.problems[start:]
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+	for _, importPath := range imports {
+		v.validateFile(importPath)
+	}
+}
+
+// checkGroups reports services in groups that have nothing to launch
+func (v *validator) checkGroups() {
+	for _, g := range v.groups {
+		for i, child := range g.group.Children {
+			service, ok := v.services[child]
+			if !ok || service.IsLaunchable() {
+				continue
+			}
+			path := fmt.Sprintf("%v.children[%v]", g.path, i)
+			v.problems = append(v.problems, Problem{
+				File:    g.file,
+				Line:    g.lines.line(path),
+				Message: fmt.Sprintf("%v: service %v in group %v has no launch command", path, child, g.group.Name),
+			})
+		}
+	}
+}
+
+// collectReferences records the config variables defined in a file, along with the names of env
+// variables it sets, and all references to variables in its string values.
+func (v *validator) collectReferences(filePath string, lines positions, top map[string]interface{}) {
+	vars, _ := top["vars"].(map[string]interface{})
+	for name := range vars {
+		v.vars[name] = true
+		v.defined[name] = true
+	}
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		switch value := value.(type) {
+		case string:
+			for _, name := range varReferences(value) {
+				v.references = append(v.references, varReference{file: filePath, path: path, lines: lines, name: name})
+			}
+		case []interface{}:
+			for i, item := range value {
+				walk(fmt.Sprintf("%v[%v]", path, i), item)
+			}
+		case map[string]interface{}:
+			var keys []string
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if key == "env" {
+					v.defineEnv(value[key])
+				}
+				walk(joinPath(path, key), value[key])
+			}
+		}
+	}
+	walk("", top)
+}
+
+// defineEnv marks the variables set by an env list as defined, since references to them
+// are expanded when commands are run
+func (v *validator) defineEnv(env interface{}) {
+	entries, _ := env.([]interface{})
+	for _, entry := range entries {
+		switch e := entry.(type) {
+		case string:
+			v.defined[strings.SplitN(e, "=", 2)[0]] = true
+		case map[string]interface{}:
+			if name, ok := e["name"].(string); ok {
+				v.defined[name] = true
+			}
+		}
+	}
+}
+
+// checkReferences reports references to names that are neither config variables nor set in the
+// environment, either by a config file or the environment in which Nedward is running
+func (v *validator) checkReferences() {
+	var vars, env []string
+	for name := range v.defined {
+		if v.vars[name] {
+			vars = append(vars, name)
+		} else {
+			env = append(env, name)
+		}
+	}
+	sort.Strings(vars)
+	sort.Strings(env)
+	for _, ref := range v.references {
+		if v.defined[ref.name] {
+			continue
+		}
+		if _, ok := os.LookupEnv(VarEnvPrefix + ref.name); ok {
+			continue
+		}
+		if _, ok := os.LookupEnv(ref.name); ok {
+			continue
+		}
+		message := fmt.Sprintf("%v: unresolved reference ${%v}", ref.path, ref.name)
+		suggestion := closest(ref.name, vars)
+		if suggestion == "" {
+			suggestion = closest(ref.name, env)
+		}
+		if suggestion != "" {
+			message = fmt.Sprintf("%v, did you mean ${%v}?", message, suggestion)
+		}
+		v.problems = append(v.problems, Problem{
+			File:    ref.file,
+			Line:    ref.lines.line(ref.path),
+			Message: message,
+		})
+	}
+}
+
+// remarshal converts a value decoded from JSON into the type of target
+func remarshal(value interface{}, target interface{}) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return json.Unmarshal(content, target)
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/nedscode/nedward/warmup"
+	"github.com/pkg/errors"
+)
+
+// VarEnvPrefix is the prefix for environment variables that set config variables.
+// For example, NEDWARD_VAR_host=localhost sets the variable "host".
+const VarEnvPrefix = "NEDWARD_VAR_"
+
+// varRefExpr matches references to config variables, in the form ${name}
+var varRefExpr = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// LoadOptions provides settings for loading a config file that are not specified by the file itself
+type LoadOptions struct {
+	// Values for config variables, taking precedence over those set in config files and the environment
+	Vars map[string]string
+	// Name of the profile to apply to services and groups that define it
+	Profile string
+}
+
+// resolveVars returns the value of each config variable, with references to other variables expanded.
+// Values from options take precedence over those from the environment, followed by the config file and
+// then any imports.
+func (c *Config) resolveVars() map[string]string {
+	var raw = make(map[string]string)
+	for key, value := range c.ImportedVars {
+		raw[key] = value
+	}
+	for key, value := range c.Vars {
+		raw[key] = value
+	}
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(env, VarEnvPrefix) {
+			parts := strings.SplitN(strings.TrimPrefix(env, VarEnvPrefix), "=", 2)
+			raw[parts[0]] = parts[1]
+		}
+	}
+	for key, value := range c.options.Vars {
+		raw[key] = value
+	}
+
+	var resolved = make(map[string]string, len(raw))
+	var resolve func(key string, expanding map[string]bool) string
+	resolve = func(key string, expanding map[string]bool) string {
+		if value, ok := resolved[key]; ok {
+			return value
+		}
+		expanding[key] = true
+		defer delete(expanding, key)
+		value := varRefExpr.ReplaceAllStringFunc(raw[key], func(ref string) string {
+			name := varRefExpr.FindStringSubmatch(ref)[1]
+			if _, ok := raw[name]; !ok || expanding[name] {
+				return ref
+			}
+			return resolve(name, expanding)
+		})
+		resolved[key] = value
+		return value
+	}
+	for key := range raw {
+		resolve(key, make(map[string]bool))
+	}
+	return resolved
+}
+
+// interpolate replaces references to config variables in s with their values.
+// References to names that are not variables are left in place, so they may be expanded
+// from the environment when commands are run.
+func interpolate(s string, vars map[string]string) string {
+	if len(vars) == 0 {
+		return s
+	}
+	return varRefExpr.ReplaceAllStringFunc(s, func(ref string) string {
+		if value, ok := vars[varRefExpr.FindStringSubmatch(ref)[1]]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+func interpolateAll(values []string, vars map[string]string) []string {
+	if values == nil {
+		return nil
+	}
+	var result = make([]string, len(values))
+	for i, value := range values {
+		result[i] = interpolate(value, vars)
+	}
+	return result
+}
+
+func interpolateSecrets(secretEnv []services.EnvSecret, vars map[string]string) []services.EnvSecret {
+	var result []services.EnvSecret
+	for _, secret := range secretEnv {
+		result = append(result, services.EnvSecret{
+			Name:        secret.Name,
+			FromCommand: interpolate(secret.FromCommand, vars),
+			FromSecret:  interpolate(secret.FromSecret, vars),
+		})
+	}
+	return result
+}
+
+// interpolateService replaces references to config variables in the string fields of a service.
+// Fields referring to shared values are copied, so the original definition is not modified.
+// The name and aliases of the service are not interpolated, so they may be referenced by groups.
+func interpolateService(service *services.ServiceConfig, vars map[string]string) error {
+	service.Description = interpolate(service.Description, vars)
+	if service.Path != nil {
+		path := interpolate(*service.Path, vars)
+		service.Path = &path
+	}
+	service.Commands = services.ServiceConfigCommands{
+		Install: interpolate(service.Commands.Install, vars),
+		Update:  interpolate(service.Commands.Update, vars),
+		Build:   interpolate(service.Commands.Build, vars),
+		Launch:  interpolate(service.Commands.Launch, vars),
+		Stop:    interpolate(service.Commands.Stop, vars),
+	}
+	service.Env = interpolateAll(service.Env, vars)
+	service.EnvFile = interpolateAll(service.EnvFile, vars)
+	service.SecretEnv = interpolateSecrets(service.SecretEnv, vars)
+	if service.LaunchChecks != nil {
+		checks := *service.LaunchChecks
+		checks.LogText = interpolate(checks.LogText, vars)
+		service.LaunchChecks = &checks
+	}
+	if service.Warmup != nil {
+		service.Warmup = &warmup.Warmup{
+			URL: interpolate(service.Warmup.URL, vars),
+		}
+	}
+	if service.Docker != nil {
+		docker := *service.Docker
+		docker.Image = interpolate(docker.Image, vars)
+		docker.Container = interpolate(docker.Container, vars)
+		docker.Ports = interpolateAll(docker.Ports, vars)
+		docker.Args = interpolateAll(docker.Args, vars)
+		service.Docker = &docker
+	}
+	if len(service.WatchJSON) > 0 {
+		var watch interface{}
+		if err := json.Unmarshal(service.WatchJSON, &watch); err != nil {
+			return errors.WithStack(err)
+		}
+		content, err := json.Marshal(interpolateValue(watch, vars))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		service.WatchJSON = content
+	}
+	return nil
+}
+
+// interpolateValue replaces references to config variables in all strings within a value decoded from JSON
+func interpolateValue(value interface{}, vars map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return interpolate(v, vars)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = interpolateValue(item, vars)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = interpolateValue(item, vars)
+		}
+		return result
+	}
+	return value
+}
+
+// varReferences returns the names of the variables referenced in s
+func varReferences(s string) []string {
+	var names []string
+	for _, match := range varRefExpr.FindAllStringSubmatch(s, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
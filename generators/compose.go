@@ -0,0 +1,327 @@
+package generators
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// composeFileNames lists the file names recognized as Docker Compose files, in order of preference
+var composeFileNames = []string{
+	"docker-compose.yml",
+	"docker-compose.yaml",
+	"compose.yml",
+	"compose.yaml",
+}
+
+// ComposeGenerator generates services and groups from Docker Compose files.
+//
+// For each compose file, a group is generated for the compose project, named using the
+// 'name' attribute of the file, or the directory containing the file if this is not set.
+// The group contains one service for each compose service, ordered such that each service
+// starts after those listed in its 'depends_on'.
+//
+// Services are run as containers with the 'docker' service type. Published ports will be
+// used for launch checks.
+type ComposeGenerator struct {
+	generatorBase
+	foundGroups   []*services.ServiceGroupConfig
+	foundServices []*services.ServiceConfig
+}
+
+// Name returns 'compose' to identify this generator
+func (v *ComposeGenerator) Name() string {
+	return "compose"
+}
+
+// VisitDir searches a directory for a Docker Compose file, generating services and a group
+// if one is found. Returns true in the first return value if a compose file was found.
+func (v *ComposeGenerator) VisitDir(path string) (bool, error) {
+	for _, fileName := range composeFileNames {
+		composePath := filepath.Join(path, fileName)
+		if _, err := os.Stat(composePath); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return false, errors.WithStack(err)
+		}
+
+		relPath, err := filepath.Rel(v.basePath, path)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+
+		group, err := parseComposeFile(composePath, relPath)
+		if err != nil {
+			return false, errors.WithMessage(err, composePath)
+		}
+		if group == nil {
+			return false, nil
+		}
+		v.foundServices = append(v.foundServices, group.Services...)
+		v.foundGroups = append(v.foundGroups, group)
+		return true, nil
+	}
+	return false, nil
+}
+
+// Groups returns a slice of groups generated on previous walks
+func (v *ComposeGenerator) Groups() []*services.ServiceGroupConfig {
+	return v.foundGroups
+}
+
+// Services returns a slice of services generated on previous walks
+func (v *ComposeGenerator) Services() []*services.ServiceConfig {
+	return v.foundServices
+}
+
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image         string           `yaml:"image"`
+	Build         composeBuild     `yaml:"build"`
+	Command       composeCommand   `yaml:"command"`
+	ContainerName string           `yaml:"container_name"`
+	Ports         []composePort    `yaml:"ports"`
+	Environment   composeMapOrList `yaml:"environment"`
+	DependsOn     composeMapOrList `yaml:"depends_on"`
+}
+
+// composeBuild handles both the short (context only) and long forms of 'build'
+type composeBuild struct {
+	Context    string `yaml:"context"`
+	Dockerfile string `yaml:"dockerfile"`
+}
+
+func (b *composeBuild) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&b.Context); err == nil {
+		return nil
+	}
+	type alias composeBuild
+	return unmarshal((*alias)(b))
+}
+
+// composeCommand handles commands specified as either a string or a list
+type composeCommand string
+
+func (c *composeCommand) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var command string
+	if err := unmarshal(&command); err == nil {
+		*c = composeCommand(command)
+		return nil
+	}
+	var parts []string
+	if err := unmarshal(&parts); err != nil {
+		return err
+	}
+	for i, part := range parts {
+		if strings.ContainsAny(part, " \t") {
+			parts[i] = "\"" + part + "\""
+		}
+	}
+	*c = composeCommand(strings.Join(parts, " "))
+	return nil
+}
+
+// composePort handles ports in the short ("8080:80") and long forms
+type composePort struct {
+	Mapping   string
+	Published int
+}
+
+func (p *composePort) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var short string
+	if err := unmarshal(&short); err == nil {
+		p.Mapping = short
+		p.Published = publishedPort(short)
+		return nil
+	}
+	var long struct {
+		Target    int    `yaml:"target"`
+		Published string `yaml:"published"`
+		HostIP    string `yaml:"host_ip"`
+	}
+	if err := unmarshal(&long); err != nil {
+		return err
+	}
+	p.Mapping = strconv.Itoa(long.Target)
+	if long.Published != "" {
+		p.Mapping = long.Published + ":" + p.Mapping
+	}
+	if long.HostIP != "" {
+		p.Mapping = long.HostIP + ":" + p.Mapping
+	}
+	p.Published = publishedPort(p.Mapping)
+	return nil
+}
+
+// publishedPort returns the single host port for a port mapping, or 0 if the host port
+// is not known in advance.
+func publishedPort(mapping string) int {
+	parts := strings.Split(strings.SplitN(mapping, "/", 2)[0], ":")
+	if len(parts) < 2 {
+		return 0
+	}
+	port, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return 0
+	}
+	return port
+}
+
+// composeMapOrList handles attributes that may be a list of strings, or a map whose keys are of interest.
+// For maps, values are appended as '=value' where they are scalars.
+type composeMapOrList []string
+
+func (m *composeMapOrList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*m = composeMapOrList(list)
+		return nil
+	}
+	var values map[string]interface{}
+	if err := unmarshal(&values); err != nil {
+		return err
+	}
+	for key, value := range values {
+		switch value.(type) {
+		case nil, map[interface{}]interface{}:
+			list = append(list, key)
+		default:
+			list = append(list, fmt.Sprintf("%v=%v", key, value))
+		}
+	}
+	sort.Strings(list)
+	*m = composeMapOrList(list)
+	return nil
+}
+
+func parseComposeFile(composePath, relPath string) (*services.ServiceGroupConfig, error) {
+	data, err := ioutil.ReadFile(composePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var compose composeFile
+	err = yaml.Unmarshal(data, &compose)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse compose file")
+	}
+	if len(compose.Services) == 0 {
+		return nil, nil
+	}
+
+	project := compose.Name
+	if project == "" {
+		project = strings.ToLower(filepath.Base(filepath.Dir(composePath)))
+	}
+
+	var names []string
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	order, err := composeStartOrder(names, compose.Services)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	group := &services.ServiceGroupConfig{
+		Name: project,
+	}
+	for _, name := range order {
+		group.Services = append(group.Services, composeToService(project, name, relPath, compose.Services[name]))
+	}
+	return group, nil
+}
+
+// composeStartOrder orders services so that each appears after the services it depends on.
+func composeStartOrder(names []string, composeServices map[string]composeService) ([]string, error) {
+	var order []string
+	var visited = make(map[string]bool)
+	var visit func(name string, chain []string) error
+	visit = func(name string, chain []string) error {
+		if done, seen := visited[name]; seen {
+			if !done {
+				return fmt.Errorf("dependency cycle: %v", strings.Join(append(chain, name), " -> "))
+			}
+			return nil
+		}
+		service, ok := composeServices[name]
+		if !ok {
+			return fmt.Errorf("service %v depends on unknown service %v", chain[len(chain)-1], name)
+		}
+		visited[name] = false
+		for _, dependency := range service.DependsOn {
+			err := visit(strings.SplitN(dependency, "=", 2)[0], append(chain, name))
+			if err != nil {
+				return err
+			}
+		}
+		visited[name] = true
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		err := visit(name, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func composeToService(project, name, relPath string, compose composeService) *services.ServiceConfig {
+	path := relPath
+	service := &services.ServiceConfig{
+		Name: name,
+		Path: &path,
+		Env:  []string(compose.Environment),
+		Type: services.ServiceTypeDocker,
+		Commands: services.ServiceConfigCommands{
+			Launch: string(compose.Command),
+		},
+		Docker: &services.DockerConfig{
+			Image:     compose.Image,
+			Container: compose.ContainerName,
+		},
+	}
+	if service.Env == nil {
+		service.Env = []string{}
+	}
+
+	if compose.Build.Context != "" {
+		if service.Docker.Image == "" {
+			service.Docker.Image = project + "-" + name + ":nedward"
+		}
+		build := "docker build -t " + service.Docker.Image
+		if compose.Build.Dockerfile != "" {
+			build += " -f " + filepath.Join(compose.Build.Context, compose.Build.Dockerfile)
+		}
+		service.Commands.Build = build + " " + compose.Build.Context
+	}
+
+	var ports []int
+	for _, port := range compose.Ports {
+		service.Docker.Ports = append(service.Docker.Ports, port.Mapping)
+		if port.Published != 0 {
+			ports = append(ports, port.Published)
+		}
+	}
+	if len(ports) > 0 {
+		service.LaunchChecks = &services.LaunchChecks{
+			Ports: ports,
+		}
+	}
+	return service
+}
@@ -20,16 +20,23 @@ type directory struct {
 
 // newDirectory builds a directory structure under the specified path
 func newDirectory(path string, parent *directory) (*directory, error) {
+	if parent != nil {
+		if ignores, ignoresDir := parent.Ignores(); ignores != nil {
+			relPath, err := filepath.Rel(ignoresDir, path)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if ignores.MatchesPath(relPath) {
+				return nil, nil
+			}
+		}
+	}
+
 	ignores, err := loadIgnores(path, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if (ignores != nil && ignores.MatchesPath(path)) ||
-		(parent != nil && parent.Ignores() != nil && parent.Ignores().MatchesPath(path)) {
-		return nil, nil
-	}
-
 	files, err := ioutil.ReadDir(path)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -72,16 +79,17 @@ func newDirectory(path string, parent *directory) (*directory, error) {
 }
 
 // Ignores returns the .nedwardignore config for this directory or any of its
-// ancestor directories.
-func (d *directory) Ignores() *ignore.GitIgnore {
+// ancestor directories, along with the directory containing the .nedwardignore file.
+// Patterns in the file are matched against paths relative to that directory.
+func (d *directory) Ignores() (*ignore.GitIgnore, string) {
 	if d.ignores != nil {
-		return d.ignores
+		return d.ignores, d.Path
 	}
 
 	if d.Parent != nil {
 		return d.Parent.Ignores()
 	}
-	return nil
+	return nil, ""
 }
 
 // SkipAll indicates that all generators should skip this directory and
@@ -5,14 +5,14 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/nedscode/nedward/services"
 )
 
 // DockerGenerator generates services from Docker files.
-// Services are generated from a Dockerfile.
+// Services are generated from a Dockerfile, and are run as containers with the
+// 'docker' service type.
 //
 // The container is build with a tag based on the directory name suffixed with ':nedward'.
 // For a Dockerfile under 'service', the tag would be 'service:nedward'.
@@ -44,7 +44,7 @@ func (v *DockerGenerator) VisitDir(path string) (bool, error) {
 		}
 
 		fPath := filepath.Join(path, f.Name())
-		expectedPorts, portCommands, err := getPorts(fPath)
+		expectedPorts, portMappings, err := getPorts(fPath)
 		if err != nil {
 			return false, errors.WithStack(err)
 		}
@@ -55,9 +55,13 @@ func (v *DockerGenerator) VisitDir(path string) (bool, error) {
 			Name: name,
 			Path: &dockerPath,
 			Env:  []string{},
+			Type: services.ServiceTypeDocker,
 			Commands: services.ServiceConfigCommands{
-				Build:  "docker build -t " + tag + " .",
-				Launch: "docker run " + strings.Join(portCommands, " ") + " " + tag,
+				Build: "docker build -t " + tag + " .",
+			},
+			Docker: &services.DockerConfig{
+				Image: tag,
+				Ports: portMappings,
 			},
 			LaunchChecks: &services.LaunchChecks{
 				Ports: expectedPorts,
@@ -76,17 +80,17 @@ func getPorts(dockerFilePath string) ([]int, []string, error) {
 		return nil, nil, errors.WithStack(err)
 	}
 	var ports []int
-	var portCommands []string
+	var portMappings []string
 	exposeExpr := regexp.MustCompile(`(?m)^(?:EXPOSE )([0-9]+)$`)
 	for _, match := range exposeExpr.FindAllStringSubmatch(string(input), -1) {
-		portCommands = append(portCommands, "-p "+match[1]+":"+match[1])
+		portMappings = append(portMappings, match[1]+":"+match[1])
 		port, err := strconv.Atoi(match[1])
 		if err != nil {
 			return nil, nil, errors.WithStack(err)
 		}
 		ports = append(ports, port)
 	}
-	return ports, portCommands, nil
+	return ports, portMappings, nil
 }
 
 // Services returns a slice of services identified in the directory walk
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"path/filepath"
 
+	"github.com/nedscode/nedward/config"
 	"github.com/pkg/errors"
 )
 
@@ -19,16 +20,20 @@ func (v *NedwardGenerator) Name() string {
 	return "nedward"
 }
 
-// VisitDir searches a directory for nedward.json files, and will store an import
+// VisitDir searches a directory for Nedward config files, and will store an import
 // for any found. Returns true in the first return value if an import was found.
 func (v *NedwardGenerator) VisitDir(path string) (bool, error) {
 	if path == v.basePath {
 		return false, nil
 	}
 	files, _ := ioutil.ReadDir(path)
+	var names = make(map[string]bool)
 	for _, f := range files {
-		if f.Name() == "nedward.json" {
-			relPath, err := filepath.Rel(v.basePath, filepath.Join(path, f.Name()))
+		names[f.Name()] = true
+	}
+	for _, name := range config.FileNames {
+		if names[name] {
+			relPath, err := filepath.Rel(v.basePath, filepath.Join(path, name))
 			if err != nil {
 				return false, errors.WithStack(err)
 			}
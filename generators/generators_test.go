@@ -2,6 +2,7 @@ package generators
 
 import (
 	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/nedscode/nedward/common"
@@ -170,6 +171,23 @@ func TestGoGenerator(t *testing.T) {
 			},
 			outErr: nil,
 		},
+		{
+			name: "Go Modules",
+			path: "testdata/go/modules/",
+			outServices: []*services.ServiceConfig{
+				{
+					Name: "server",
+					Path: common.StringToStringPointer("app/cmd/server"),
+					Env:  []string{},
+					Commands: services.ServiceConfigCommands{
+						Build:  "go build -o $NEDWARD_BIN_DIR/server .",
+						Launch: "$NEDWARD_BIN_DIR/server",
+					},
+					WatchJSON: []byte(`{"include":["app/cmd/server","app/internal/handler","lib"]}`),
+				},
+			},
+			outErr: nil,
+		},
 		{
 			name: "Go with symlink",
 			path: "testdata/symlinked/test/",
@@ -225,9 +243,13 @@ func TestDockerGenerator(t *testing.T) {
 					Name: "service",
 					Path: common.StringToStringPointer("service"),
 					Env:  []string{},
+					Type: services.ServiceTypeDocker,
 					Commands: services.ServiceConfigCommands{
-						Build:  "docker build -t service:nedward .",
-						Launch: "docker run -p 80:80 service:nedward",
+						Build: "docker build -t service:nedward .",
+					},
+					Docker: &services.DockerConfig{
+						Image: "service:nedward",
+						Ports: []string{"80:80"},
 					},
 					LaunchChecks: &services.LaunchChecks{
 						Ports: []int{80},
@@ -243,9 +265,13 @@ func TestDockerGenerator(t *testing.T) {
 					Name: "child",
 					Path: common.StringToStringPointer("parent/child"),
 					Env:  []string{},
+					Type: services.ServiceTypeDocker,
 					Commands: services.ServiceConfigCommands{
-						Build:  "docker build -t child:nedward .",
-						Launch: "docker run -p 80:80 child:nedward",
+						Build: "docker build -t child:nedward .",
+					},
+					Docker: &services.DockerConfig{
+						Image: "child:nedward",
+						Ports: []string{"80:80"},
 					},
 					LaunchChecks: &services.LaunchChecks{
 						Ports: []int{80},
@@ -255,9 +281,13 @@ func TestDockerGenerator(t *testing.T) {
 					Name: "parent",
 					Path: common.StringToStringPointer("parent"),
 					Env:  []string{},
+					Type: services.ServiceTypeDocker,
 					Commands: services.ServiceConfigCommands{
-						Build:  "docker build -t parent:nedward .",
-						Launch: "docker run -p 80:80 parent:nedward",
+						Build: "docker build -t parent:nedward .",
+					},
+					Docker: &services.DockerConfig{
+						Image: "parent:nedward",
+						Ports: []string{"80:80"},
 					},
 					LaunchChecks: &services.LaunchChecks{
 						Ports: []int{80},
@@ -282,9 +312,13 @@ func TestDockerGenerator(t *testing.T) {
 					Name: "parent",
 					Path: common.StringToStringPointer("parent"),
 					Env:  []string{},
+					Type: services.ServiceTypeDocker,
 					Commands: services.ServiceConfigCommands{
-						Build:  "docker build -t parent:nedward .",
-						Launch: "docker run -p 80:80 parent:nedward",
+						Build: "docker build -t parent:nedward .",
+					},
+					Docker: &services.DockerConfig{
+						Image: "parent:nedward",
+						Ports: []string{"80:80"},
 					},
 					LaunchChecks: &services.LaunchChecks{
 						Ports: []int{80},
@@ -317,6 +351,60 @@ func TestDockerGenerator(t *testing.T) {
 }
 
 func TestProcfileGenerator(t *testing.T) {
+	var dotEnv = []string{"PORT=3000", "METRICS_PORT=9102", "DATABASE_URL=postgres://localhost/app"}
+	var procfileWeb = &services.ServiceConfig{
+		Name: "app-web",
+		Path: common.StringToStringPointer("app"),
+		Env:  dotEnv,
+		Commands: services.ServiceConfigCommands{
+			Launch: "bundle exec puma -C config/puma.rb",
+		},
+		LaunchChecks: &services.LaunchChecks{
+			Ports: []int{3000},
+		},
+	}
+	var procfileWorker = &services.ServiceConfig{
+		Name: "app-worker",
+		Path: common.StringToStringPointer("app"),
+		Env:  dotEnv,
+		Commands: services.ServiceConfigCommands{
+			Launch: "bundle exec sidekiq",
+		},
+	}
+	var procfileAPI = &services.ServiceConfig{
+		Name: "app-api",
+		Path: common.StringToStringPointer("app"),
+		Env:  []string{"METRICS_PORT=9102", "DATABASE_URL=postgres://localhost/app", "PORT=4000", "API_MODE=dev"},
+		Commands: services.ServiceConfigCommands{
+			Launch: "bin/api",
+		},
+		LaunchChecks: &services.LaunchChecks{
+			Ports: []int{4000},
+		},
+	}
+	var procfileMetrics = &services.ServiceConfig{
+		Name: "app-metrics",
+		Path: common.StringToStringPointer("app"),
+		Env:  dotEnv,
+		Commands: services.ServiceConfigCommands{
+			Launch: "./metrics --listen :${METRICS_PORT}",
+		},
+		LaunchChecks: &services.LaunchChecks{
+			Ports: []int{9102},
+		},
+	}
+	var procfileAssets = &services.ServiceConfig{
+		Name: "app-assets",
+		Path: common.StringToStringPointer("app"),
+		Env:  dotEnv,
+		Commands: services.ServiceConfigCommands{
+			Launch: "bin/webpack-dev-server --port 3035",
+		},
+		LaunchChecks: &services.LaunchChecks{
+			Ports: []int{3035},
+		},
+	}
+
 	var tests = []struct {
 		name        string
 		path        string
@@ -371,6 +459,21 @@ func TestProcfileGenerator(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Procfile.dev with .env",
+			path: "testdata/procfiles/dev/",
+			outServices: []*services.ServiceConfig{
+				procfileAPI, procfileAssets, procfileMetrics, procfileWeb, procfileWorker,
+			},
+			outGroups: []*services.ServiceGroupConfig{
+				{
+					Name: "app",
+					Services: []*services.ServiceConfig{
+						procfileWeb, procfileWorker, procfileAPI, procfileMetrics, procfileAssets,
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -392,3 +495,535 @@ func TestProcfileGenerator(t *testing.T) {
 		})
 	}
 }
+
+func TestComposeGenerator(t *testing.T) {
+	var api = &services.ServiceConfig{
+		Name: "api",
+		Path: common.StringToStringPointer("store"),
+		Env:  []string{"DB_HOST=db"},
+		Type: services.ServiceTypeDocker,
+		Commands: services.ServiceConfigCommands{
+			Build:  "docker build -t store-api:nedward ./api",
+			Launch: "serve --port 8080",
+		},
+		Docker: &services.DockerConfig{
+			Image: "store-api:nedward",
+			Ports: []string{"8080:8080"},
+		},
+		LaunchChecks: &services.LaunchChecks{
+			Ports: []int{8080},
+		},
+	}
+	var cache = &services.ServiceConfig{
+		Name: "cache",
+		Path: common.StringToStringPointer("store"),
+		Env:  []string{},
+		Type: services.ServiceTypeDocker,
+		Docker: &services.DockerConfig{
+			Image:     "redis",
+			Container: "store-cache",
+			Ports:     []string{"127.0.0.1:6379:6379", "9000"},
+		},
+		LaunchChecks: &services.LaunchChecks{
+			Ports: []int{6379},
+		},
+	}
+	var db = &services.ServiceConfig{
+		Name: "db",
+		Path: common.StringToStringPointer("store"),
+		Env:  []string{"POSTGRES_USER=docker", "POSTGRES_PASSWORD=docker"},
+		Type: services.ServiceTypeDocker,
+		Docker: &services.DockerConfig{
+			Image: "postgres",
+			Ports: []string{"5432:5432"},
+		},
+		LaunchChecks: &services.LaunchChecks{
+			Ports: []int{5432},
+		},
+	}
+
+	var tests = []struct {
+		name        string
+		path        string
+		targets     []string
+		outServices []*services.ServiceConfig
+		outGroups   []*services.ServiceGroupConfig
+		outImports  []string
+		outErr      error
+	}{
+		{
+			name:        "Compose project",
+			path:        "testdata/compose/project/",
+			outServices: []*services.ServiceConfig{api, cache, db},
+			outGroups: []*services.ServiceGroupConfig{
+				{
+					Name:     "store",
+					Services: []*services.ServiceConfig{db, cache, api},
+				},
+			},
+		},
+		{
+			name:   "Compose dependency cycle",
+			path:   "testdata/compose/cycle/",
+			outErr: errors.New("testdata/compose/cycle/app/compose.yaml: dependency cycle: first -> second -> first"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			gc := &GeneratorCollection{
+				Generators: []Generator{&ComposeGenerator{}},
+				Path:       test.path,
+				Targets:    test.targets,
+			}
+			err := gc.Generate()
+			services := gc.Services()
+			groups := gc.Groups()
+			imports := gc.Imports()
+			must.BeEqual(t, test.outServices, services, "services did not match.")
+			must.BeEqual(t, test.outGroups, groups, "groups did not match.")
+			must.BeEqual(t, test.outImports, imports, "imports did not match.")
+			must.BeEqualErrors(t, test.outErr, err, "errors did not match.")
+		})
+	}
+}
+
+func TestNodeGenerator(t *testing.T) {
+	var tests = []struct {
+		name        string
+		path        string
+		targets     []string
+		outServices []*services.ServiceConfig
+		outGroups   []*services.ServiceGroupConfig
+		outImports  []string
+		outErr      error
+	}{
+		{
+			name: "Node projects",
+			path: "testdata/node/",
+			outServices: []*services.ServiceConfig{
+				{
+					Name: "dashboard",
+					Path: common.StringToStringPointer("dashboard"),
+					Env:  []string{},
+					Commands: services.ServiceConfigCommands{
+						Install: "yarn install",
+						Launch:  "yarn dev",
+					},
+					WatchJSON: []byte(`{"include":["dashboard"],"exclude":["dashboard/node_modules"]}`),
+				},
+				{
+					Name: "web",
+					Path: common.StringToStringPointer("web"),
+					Env:  []string{},
+					Commands: services.ServiceConfigCommands{
+						Install: "npm ci",
+						Build:   "npm run build",
+						Launch:  "npm start",
+					},
+					WatchJSON: []byte(`{"include":["web/src"],"exclude":["web/node_modules"]}`),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			gc := &GeneratorCollection{
+				Generators: []Generator{&NodeGenerator{}},
+				Path:       test.path,
+				Targets:    test.targets,
+			}
+			err := gc.Generate()
+			services := gc.Services()
+			groups := gc.Groups()
+			imports := gc.Imports()
+			must.BeEqual(t, test.outServices, services, "services did not match.")
+			must.BeEqual(t, test.outGroups, groups, "groups did not match.")
+			must.BeEqual(t, test.outImports, imports, "imports did not match.")
+			must.BeEqualErrors(t, test.outErr, err, "errors did not match.")
+		})
+	}
+}
+
+func TestMavenGenerator(t *testing.T) {
+	var api = &services.ServiceConfig{
+		Name: "platform-api",
+		Path: common.StringToStringPointer("multi"),
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Build:  "./mvnw -pl api -am install -DskipTests",
+			Launch: "./mvnw -pl api spring-boot:run",
+		},
+		LaunchChecks: &services.LaunchChecks{
+			LogText: "Started ApiApplication in",
+		},
+	}
+	var web = &services.ServiceConfig{
+		Name: "platform-web",
+		Path: common.StringToStringPointer("multi"),
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Build:  "./mvnw -pl web -am install -DskipTests",
+			Launch: "./mvnw -pl web spring-boot:run",
+		},
+	}
+	var demo = &services.ServiceConfig{
+		Name: "demo",
+		Path: common.StringToStringPointer("single"),
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Build:  "mvn package -DskipTests",
+			Launch: "mvn spring-boot:run",
+		},
+		LaunchChecks: &services.LaunchChecks{
+			LogText: "Started DemoApplication in",
+		},
+	}
+
+	var tests = []struct {
+		name        string
+		path        string
+		targets     []string
+		outServices []*services.ServiceConfig
+		outGroups   []*services.ServiceGroupConfig
+		outImports  []string
+		outErr      error
+	}{
+		{
+			name:        "Maven projects",
+			path:        "testdata/maven/",
+			outServices: []*services.ServiceConfig{demo, api, web},
+			outGroups: []*services.ServiceGroupConfig{
+				{
+					Name:     "platform",
+					Services: []*services.ServiceConfig{api, web},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			gc := &GeneratorCollection{
+				Generators: []Generator{&MavenGenerator{}},
+				Path:       test.path,
+				Targets:    test.targets,
+			}
+			err := gc.Generate()
+			services := gc.Services()
+			groups := gc.Groups()
+			imports := gc.Imports()
+			must.BeEqual(t, test.outServices, services, "services did not match.")
+			must.BeEqual(t, test.outGroups, groups, "groups did not match.")
+			must.BeEqual(t, test.outImports, imports, "imports did not match.")
+			must.BeEqualErrors(t, test.outErr, err, "errors did not match.")
+		})
+	}
+}
+
+func TestGradleGenerator(t *testing.T) {
+	var api = &services.ServiceConfig{
+		Name: "api",
+		Path: common.StringToStringPointer("multi"),
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Build:  "./gradlew :api:build -x test",
+			Launch: "./gradlew :api:bootRun",
+		},
+		LaunchChecks: &services.LaunchChecks{
+			LogText: "Started ApiApplication in",
+		},
+	}
+	var worker = &services.ServiceConfig{
+		Name: "worker",
+		Path: common.StringToStringPointer("multi"),
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Build:  "./gradlew :worker:build -x test",
+			Launch: "./gradlew :worker:run",
+		},
+	}
+	var single = &services.ServiceConfig{
+		Name: "single",
+		Path: common.StringToStringPointer("single"),
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Build:  "gradle build -x test",
+			Launch: "gradle run",
+		},
+	}
+
+	var tests = []struct {
+		name        string
+		path        string
+		targets     []string
+		outServices []*services.ServiceConfig
+		outGroups   []*services.ServiceGroupConfig
+		outImports  []string
+		outErr      error
+	}{
+		{
+			name:        "Gradle projects",
+			path:        "testdata/gradle/",
+			outServices: []*services.ServiceConfig{api, single, worker},
+			outGroups: []*services.ServiceGroupConfig{
+				{
+					Name:     "pipeline",
+					Services: []*services.ServiceConfig{api, worker},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			gc := &GeneratorCollection{
+				Generators: []Generator{&GradleGenerator{}},
+				Path:       test.path,
+				Targets:    test.targets,
+			}
+			err := gc.Generate()
+			services := gc.Services()
+			groups := gc.Groups()
+			imports := gc.Imports()
+			must.BeEqual(t, test.outServices, services, "services did not match.")
+			must.BeEqual(t, test.outGroups, groups, "groups did not match.")
+			must.BeEqual(t, test.outImports, imports, "imports did not match.")
+			must.BeEqualErrors(t, test.outErr, err, "errors did not match.")
+		})
+	}
+}
+
+func TestPythonGenerator(t *testing.T) {
+	var tests = []struct {
+		name        string
+		path        string
+		targets     []string
+		outServices []*services.ServiceConfig
+		outGroups   []*services.ServiceGroupConfig
+		outImports  []string
+		outErr      error
+	}{
+		{
+			name: "Python projects",
+			path: "testdata/python/",
+			outServices: []*services.ServiceConfig{
+				{
+					Name: "django",
+					Path: common.StringToStringPointer("django"),
+					Env:  []string{"VIRTUAL_ENV=$NEDWARD_VENV_DIR/django", "PATH=$VIRTUAL_ENV/bin:$PATH"},
+					Commands: services.ServiceConfigCommands{
+						Install: `sh -c "python3 -m venv $VIRTUAL_ENV && $VIRTUAL_ENV/bin/pip install -r requirements.txt"`,
+						Launch:  "python manage.py runserver --noreload",
+					},
+					LaunchChecks: &services.LaunchChecks{
+						Ports: []int{8000},
+					},
+					WatchJSON: []byte(`{"include":["django"],"exclude":["**/__pycache__"]}`),
+				},
+				{
+					Name: "ingest",
+					Path: common.StringToStringPointer("ingest"),
+					Env:  []string{"VIRTUAL_ENV=$NEDWARD_VENV_DIR/ingest", "PATH=$VIRTUAL_ENV/bin:$PATH"},
+					Commands: services.ServiceConfigCommands{
+						Install: `sh -c "python3 -m venv $VIRTUAL_ENV && $VIRTUAL_ENV/bin/pip install -e ."`,
+						Launch:  "ingest",
+					},
+					WatchJSON: []byte(`{"include":["ingest"],"exclude":["**/__pycache__"]}`),
+				},
+				{
+					Name: "reports",
+					Path: common.StringToStringPointer("reports"),
+					Env:  []string{"VIRTUAL_ENV=$NEDWARD_VENV_DIR/reports", "PATH=$VIRTUAL_ENV/bin:$PATH"},
+					Commands: services.ServiceConfigCommands{
+						Install: `sh -c "python3 -m venv $VIRTUAL_ENV && pipenv install --dev"`,
+						Launch:  "pipenv run serve",
+					},
+					WatchJSON: []byte(`{"include":["reports"],"exclude":["**/__pycache__"]}`),
+				},
+				{
+					Name: "worker",
+					Path: common.StringToStringPointer("worker"),
+					Env:  []string{"VIRTUAL_ENV=$NEDWARD_VENV_DIR/worker", "PATH=$VIRTUAL_ENV/bin:$PATH"},
+					Commands: services.ServiceConfigCommands{
+						Install: `sh -c "python3 -m venv $VIRTUAL_ENV && $VIRTUAL_ENV/bin/pip install -r requirements.txt"`,
+						Launch:  "gunicorn app:app",
+					},
+					WatchJSON: []byte(`{"include":["worker"],"exclude":["**/__pycache__"]}`),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			gc := &GeneratorCollection{
+				Generators: []Generator{&PythonGenerator{}},
+				Path:       test.path,
+				Targets:    test.targets,
+			}
+			err := gc.Generate()
+			services := gc.Services()
+			groups := gc.Groups()
+			imports := gc.Imports()
+			must.BeEqual(t, test.outServices, services, "services did not match.")
+			must.BeEqual(t, test.outGroups, groups, "groups did not match.")
+			must.BeEqual(t, test.outImports, imports, "imports did not match.")
+			must.BeEqualErrors(t, test.outErr, err, "errors did not match.")
+		})
+	}
+}
+
+func TestFindPlugins(t *testing.T) {
+	plugins, err := FindPlugins("testdata/plugins/missing", "testdata/plugins/bin", "testdata/plugins/bin")
+	must.BeNoError(t, err)
+
+	var names []string
+	for _, plugin := range plugins {
+		names = append(names, plugin.Name())
+	}
+	must.BeEqual(t, []string{"broken", "script"}, names, "plugins did not match.")
+}
+
+func TestPluginGenerator(t *testing.T) {
+	var app = &services.ServiceConfig{
+		Name: "app",
+		Path: common.StringToStringPointer("app"),
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Launch: "./run.sh",
+		},
+	}
+	var job = &services.ServiceConfig{
+		Name: "job",
+		Path: common.StringToStringPointer("tools/job"),
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Launch: "./run.sh",
+		},
+	}
+
+	var tests = []struct {
+		name        string
+		path        string
+		plugin      string
+		targets     []string
+		outServices []*services.ServiceConfig
+		outGroups   []*services.ServiceGroupConfig
+		outImports  []string
+		outErr      error
+	}{
+		{
+			name:        "Plugin generates services and groups",
+			path:        "testdata/plugins/project",
+			plugin:      "testdata/plugins/bin/nedward-generator-script",
+			outServices: []*services.ServiceConfig{app, job},
+			outGroups: []*services.ServiceGroupConfig{
+				{
+					Name:     "project",
+					Services: []*services.ServiceConfig{app, job},
+				},
+			},
+		},
+		{
+			name:   "Plugin fails",
+			path:   "testdata/plugins/project",
+			plugin: "testdata/plugins/bin/nedward-generator-broken",
+			outErr: errors.New("generator plugin broken failed in .: exit status 1: could not read project"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			plugin, err := filepath.Abs(test.plugin)
+			must.BeNoError(t, err)
+			gc := &GeneratorCollection{
+				Generators: []Generator{NewPluginGenerator(plugin)},
+				Path:       test.path,
+				Targets:    test.targets,
+			}
+			err = gc.Generate()
+			must.BeEqualErrors(t, test.outErr, err, "errors did not match.")
+			if err != nil {
+				return
+			}
+			services := gc.Services()
+			groups := gc.Groups()
+			imports := gc.Imports()
+			must.BeEqual(t, test.outServices, services, "services did not match.")
+			must.BeEqual(t, test.outGroups, groups, "groups did not match.")
+			must.BeEqual(t, test.outImports, imports, "imports did not match.")
+		})
+	}
+}
+
+func TestMakeGenerator(t *testing.T) {
+	var tests = []struct {
+		name        string
+		path        string
+		targets     []string
+		outServices []*services.ServiceConfig
+		outGroups   []*services.ServiceGroupConfig
+		outImports  []string
+		outErr      error
+	}{
+		{
+			name: "Makefiles",
+			path: "testdata/make/",
+			outServices: []*services.ServiceConfig{
+				{
+					Name: "api",
+					Path: common.StringToStringPointer("api"),
+					Env:  []string{},
+					Commands: services.ServiceConfigCommands{
+						Install: "make install",
+						Build:   "make build",
+						Launch:  "make run",
+					},
+				},
+				{
+					Name: "legacy",
+					Path: common.StringToStringPointer("nested/legacy"),
+					Env:  []string{},
+					Commands: services.ServiceConfigCommands{
+						Launch: "make run",
+					},
+				},
+				{
+					Name: "web",
+					Path: common.StringToStringPointer("web"),
+					Env:  []string{},
+					Commands: services.ServiceConfigCommands{
+						Launch: "make serve",
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			gc := &GeneratorCollection{
+				Generators: []Generator{&MakeGenerator{}},
+				Path:       test.path,
+				Targets:    test.targets,
+			}
+			err := gc.Generate()
+			services := gc.Services()
+			groups := gc.Groups()
+			imports := gc.Imports()
+			must.BeEqual(t, test.outServices, services, "services did not match.")
+			must.BeEqual(t, test.outGroups, groups, "groups did not match.")
+			must.BeEqual(t, test.outImports, imports, "imports did not match.")
+			must.BeEqualErrors(t, test.outErr, err, "errors did not match.")
+		})
+	}
+}
@@ -11,11 +11,16 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/nedscode/nedward/home"
 	"github.com/nedscode/nedward/services"
 	"github.com/pkg/errors"
 )
 
-// GoGenerator generates go services from main packages
+// GoGenerator generates go services from main packages.
+//
+// Packages within a Go module are built into a per-service binary under the Nedward
+// state directory, with watch paths for the packages in the module on which they depend.
+// Packages outside a module are built with 'go install' and launched from the GOPATH.
 type GoGenerator struct {
 	generatorBase
 	foundServices []*services.ServiceConfig
@@ -54,7 +59,12 @@ func (v *GoGenerator) VisitDir(path string) (bool, error) {
 			if err != nil {
 				return false, errors.WithStack(err)
 			}
-			service, err := v.goService(packageName, packagePath)
+			var service *services.ServiceConfig
+			if findModuleRoot(path) != "" {
+				service, err = v.goModuleService(packageName, packagePath, path)
+			} else {
+				service, err = v.goService(packageName, packagePath)
+			}
 			if err != nil {
 				return false, errors.WithStack(err)
 			}
@@ -86,6 +96,97 @@ func (v *GoGenerator) goService(name, packagePath string) (*services.ServiceConf
 	return service, nil
 }
 
+func (v *GoGenerator) goModuleService(name, packagePath, fullPath string) (*services.ServiceConfig, error) {
+	binary := "$" + home.BinDirEnv + "/" + name
+	service := &services.ServiceConfig{
+		Name: name,
+		Path: &packagePath,
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Build:  "go build -o " + binary + " .",
+			Launch: binary,
+		},
+	}
+
+	watchPaths, err := v.getModuleDependencyDirs(fullPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = service.SetWatch(services.ServiceWatch{
+		IncludedPaths: watchPaths,
+	})
+	return service, errors.WithStack(err)
+}
+
+// findModuleRoot returns the directory containing the go.mod for the module that
+// contains path, or an empty string if path is not within a module.
+func findModuleRoot(path string) string {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// getModuleDependencyDirs uses 'go list' to find the directories of all packages on which the
+// package in fullPath depends that are part of the main module or replaced by a local directory.
+// Paths are returned relative to the base path for this walk.
+func (v *GoGenerator) getModuleDependencyDirs(fullPath string) ([]string, error) {
+	cmd := exec.Command(
+		"go", "list", "-deps",
+		"-f", "{{with .Module}}{{if or .Main (and .Replace (not .Replace.Version))}}{{$.Dir}}{{end}}{{end}}",
+		".",
+	)
+	cmd.Dir = fullPath
+	cmd.Env = append(os.Environ(), "GO111MODULE=on")
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		return nil, errors.WithMessage(err, "listing dependencies: "+strings.TrimSpace(errBuf.String()))
+	}
+
+	// 'go list' reports directories with symlinks resolved
+	basePath, err := filepath.Abs(v.basePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	basePath, err = filepath.EvalSymlinks(basePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Each package directory is listed individually, as watches do not include subdirectories
+	var found = make(map[string]struct{})
+	var dirs []string
+	for _, dir := range strings.Split(out.String(), "\n") {
+		if dir == "" {
+			continue
+		}
+		rel, err := filepath.Rel(basePath, dir)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if _, ok := found[rel]; !ok {
+			found[rel] = struct{}{}
+			dirs = append(dirs, rel)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
 func (v *GoGenerator) createWatch(service *services.ServiceConfig) (services.ServiceWatch, error) {
 	return services.ServiceWatch{
 		Service:       service,
@@ -0,0 +1,179 @@
+package generators
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+var (
+	gradleSpringBootExpr  = regexp.MustCompile(`(?m)^\s*(?:id\s*\(?\s*["']org\.springframework\.boot["']|apply\s+plugin\s*:\s*["']org\.springframework\.boot["'])`)
+	gradleApplicationExpr = regexp.MustCompile(`(?m)^\s*(?:application\b|id\s*\(?\s*["']application["']|apply\s+plugin\s*:\s*["']application["'])`)
+	gradleIncludeExpr     = regexp.MustCompile(`(?m)^\s*include\b(.*)$`)
+	gradleProjectExpr     = regexp.MustCompile(`["']([^"']+)["']`)
+	gradleRootNameExpr    = regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+)
+
+// GradleGenerator generates services from Gradle projects that use the 'application' or
+// Spring Boot plugins. Both Groovy (build.gradle) and Kotlin (build.gradle.kts) build files
+// are supported.
+//
+// Spring Boot projects are launched with the 'bootRun' task, and other applications with the 'run' task.
+//
+// For multi-project builds, identified by the projects included in settings.gradle, a group is
+// generated for the root project, containing a service for each project using either plugin.
+// Tasks for these projects are run from the root project.
+//
+// The Gradle wrapper (gradlew) will be used where present.
+type GradleGenerator struct {
+	generatorBase
+	foundGroups   []*services.ServiceGroupConfig
+	foundServices []*services.ServiceConfig
+}
+
+// Name returns 'gradle' to identify this generator
+func (v *GradleGenerator) Name() string {
+	return "gradle"
+}
+
+// VisitDir checks a directory for a Gradle build. If found, services will be generated for
+// the root project and any included projects that use the application or Spring Boot plugins.
+// Once a build has been found, true, filepath.SkipDir will be returned to ensure included projects
+// are not visited again.
+func (v *GradleGenerator) VisitDir(path string) (bool, error) {
+	settings, err := readFirst(path, "settings.gradle", "settings.gradle.kts")
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	buildFile, err := readFirst(path, "build.gradle", "build.gradle.kts")
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if settings == nil && buildFile == nil {
+		return false, nil
+	}
+
+	relPath, err := filepath.Rel(v.basePath, path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	gradle := buildTool(path, "gradle", "gradlew")
+
+	rootName := filepath.Base(path)
+	if match := gradleRootNameExpr.FindSubmatch(settings); match != nil {
+		rootName = string(match[1])
+	}
+
+	var found []*services.ServiceConfig
+	if service, err := gradleService(rootName, "", path, relPath, gradle, buildFile); err != nil {
+		return false, errors.WithStack(err)
+	} else if service != nil {
+		found = append(found, service)
+	}
+
+	for _, project := range gradleIncludedProjects(settings) {
+		projectPath := filepath.Join(path, filepath.FromSlash(strings.Replace(strings.Trim(project, ":"), ":", "/", -1)))
+		projectBuild, err := readFirst(projectPath, "build.gradle", "build.gradle.kts")
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		service, err := gradleService(jvmProjectName(projectPath), ":"+strings.Trim(project, ":"), projectPath, relPath, gradle, projectBuild)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		if service != nil {
+			found = append(found, service)
+		}
+	}
+
+	if len(found) == 0 {
+		return false, nil
+	}
+	v.foundServices = append(v.foundServices, found...)
+	if settings != nil && len(gradleIncludedProjects(settings)) > 0 {
+		v.foundGroups = append(v.foundGroups, &services.ServiceGroupConfig{
+			Name:     rootName,
+			Services: found,
+		})
+	}
+	return true, filepath.SkipDir
+}
+
+// gradleService creates a service for a project if its build file applies the application or
+// Spring Boot plugins. Tasks are prefixed with taskPrefix to address projects in a multi-project build.
+func gradleService(name, taskPrefix, projectPath, relPath, gradle string, buildFile []byte) (*services.ServiceConfig, error) {
+	var runTask string
+	var launchChecks *services.LaunchChecks
+	switch {
+	case gradleSpringBootExpr.Match(buildFile):
+		runTask = "bootRun"
+		var err error
+		launchChecks, err = springBootLaunchChecks(projectPath)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	case gradleApplicationExpr.Match(buildFile):
+		runTask = "run"
+	default:
+		return nil, nil
+	}
+
+	servicePath := relPath
+	return &services.ServiceConfig{
+		Name: name,
+		Path: &servicePath,
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Build:  gradle + " " + gradleTask(taskPrefix, "build") + " -x test",
+			Launch: gradle + " " + gradleTask(taskPrefix, runTask),
+		},
+		LaunchChecks: launchChecks,
+	}, nil
+}
+
+// gradleTask returns the path to a task in the project identified by taskPrefix
+func gradleTask(taskPrefix, task string) string {
+	if taskPrefix == "" {
+		return task
+	}
+	return taskPrefix + ":" + task
+}
+
+// gradleIncludedProjects returns the project paths included in a settings file
+func gradleIncludedProjects(settings []byte) []string {
+	var projects []string
+	for _, include := range gradleIncludeExpr.FindAllSubmatch(settings, -1) {
+		for _, project := range gradleProjectExpr.FindAllSubmatch(include[1], -1) {
+			projects = append(projects, string(project[1]))
+		}
+	}
+	return projects
+}
+
+// readFirst returns the content of the first of the named files that exists in path, or
+// nil if none exist.
+func readFirst(path string, names ...string) ([]byte, error) {
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(path, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		return data, errors.WithStack(err)
+	}
+	return nil, nil
+}
+
+// Groups returns a slice of groups generated on previous walks
+func (v *GradleGenerator) Groups() []*services.ServiceGroupConfig {
+	return v.foundGroups
+}
+
+// Services returns a slice of services generated on previous walks
+func (v *GradleGenerator) Services() []*services.ServiceConfig {
+	return v.foundServices
+}
@@ -0,0 +1,66 @@
+package generators
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+var springBootApplicationExpr = regexp.MustCompile(`@SpringBootApplication[^{]*?\bclass\s+(\w+)`)
+
+// springBootLaunchChecks returns launch checks that wait for the "Started ... in N seconds" line
+// logged by a Spring Boot application. The name of the application class is found by searching
+// the sources under path for @SpringBootApplication. Returns nil if no application class is found.
+func springBootLaunchChecks(path string) (*services.LaunchChecks, error) {
+	var appClass string
+	for _, sourceDir := range []string{"src/main/java", "src/main/kotlin"} {
+		root := filepath.Join(path, sourceDir)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || appClass != "" {
+				return err
+			}
+			if ext := filepath.Ext(filePath); ext != ".java" && ext != ".kt" {
+				return nil
+			}
+			source, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if match := springBootApplicationExpr.FindSubmatch(source); match != nil {
+				appClass = string(match[1])
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if appClass == "" {
+		return nil, nil
+	}
+	return &services.LaunchChecks{
+		LogText: "Started " + appClass + " in",
+	}, nil
+}
+
+// buildTool returns the command to run a build tool in the directory path, preferring a
+// wrapper script if one is present.
+func buildTool(path, tool, wrapper string) string {
+	if _, err := os.Stat(filepath.Join(path, wrapper)); err == nil {
+		return "./" + wrapper
+	}
+	return tool
+}
+
+// jvmProjectName converts a module path, such as "services/api", into a service name.
+func jvmProjectName(modulePath string) string {
+	return filepath.Base(strings.Trim(filepath.ToSlash(modulePath), "/"))
+}
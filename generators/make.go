@@ -0,0 +1,139 @@
+package generators
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+// makefileNames lists the file names recognized as Makefiles, in the order make searches for them
+var makefileNames = []string{
+	"GNUmakefile",
+	"makefile",
+	"Makefile",
+}
+
+// makeLaunchTargets lists targets that conventionally launch a project, in order of preference
+var makeLaunchTargets = []string{
+	"run",
+	"serve",
+	"start",
+}
+
+// MakeGenerator generates services from projects with a Makefile defining conventional targets.
+//
+// A service is generated for each Makefile with a 'run', 'serve' or 'start' target, which will be
+// used to launch the service. If present, the 'build' and 'install' targets will be used to build
+// and install the service.
+//
+// The service is named for the directory containing the Makefile.
+type MakeGenerator struct {
+	generatorBase
+	foundServices []*services.ServiceConfig
+}
+
+// Name returns 'make' to identify this generator
+func (v *MakeGenerator) Name() string {
+	return "make"
+}
+
+// VisitDir searches a directory for a Makefile, and will store a service if it defines a
+// target to launch the project. Returns true in the first return value if a service was found.
+func (v *MakeGenerator) VisitDir(path string) (bool, error) {
+	var makefilePath string
+	for _, name := range makefileNames {
+		if fileExists(filepath.Join(path, name)) {
+			makefilePath = filepath.Join(path, name)
+			break
+		}
+	}
+	if makefilePath == "" {
+		return false, nil
+	}
+
+	targets, err := readMakeTargets(makefilePath)
+	if err != nil {
+		return false, errors.WithMessage(err, makefilePath)
+	}
+
+	var launch string
+	for _, target := range makeLaunchTargets {
+		if targets[target] {
+			launch = "make " + target
+			break
+		}
+	}
+	if launch == "" {
+		return false, nil
+	}
+
+	relPath, err := filepath.Rel(v.basePath, path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	service := &services.ServiceConfig{
+		Name: filepath.Base(path),
+		Path: &relPath,
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Launch: launch,
+		},
+	}
+	if targets["build"] {
+		service.Commands.Build = "make build"
+	}
+	if targets["install"] {
+		service.Commands.Install = "make install"
+	}
+
+	v.foundServices = append(v.foundServices, service)
+	return true, nil
+}
+
+// Services returns the services generated during the last walk
+func (v *MakeGenerator) Services() []*services.ServiceConfig {
+	return v.foundServices
+}
+
+// readMakeTargets returns the set of explicit targets defined in a Makefile.
+// Special targets (such as .PHONY), pattern rules and targets defined using variables are ignored.
+func readMakeTargets(makefilePath string) (map[string]bool, error) {
+	makefile, err := os.Open(makefilePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer makefile.Close()
+
+	var targets = make(map[string]bool)
+	scanner := bufio.NewScanner(makefile)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Recipe lines
+		if strings.HasPrefix(line, "\t") {
+			continue
+		}
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		colon := strings.Index(line, ":")
+		if colon <= 0 {
+			continue
+		}
+		// Variable assignments, such as VAR := value
+		if strings.HasPrefix(line[colon:], ":=") || strings.HasPrefix(line[colon:], "::=") || strings.Contains(line[:colon], "=") {
+			continue
+		}
+		for _, target := range strings.Fields(line[:colon]) {
+			if strings.HasPrefix(target, ".") || strings.ContainsAny(target, "%$()") {
+				continue
+			}
+			targets[target] = true
+		}
+	}
+	return targets, errors.WithStack(scanner.Err())
+}
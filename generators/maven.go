@@ -0,0 +1,185 @@
+package generators
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+// MavenGenerator generates services from Maven projects that use the Spring Boot plugin.
+//
+// A project with a pom.xml that includes spring-boot-maven-plugin will generate a single
+// service, built with 'mvn package' and launched with 'mvn spring-boot:run'.
+//
+// For multi-module projects, a group is generated for the root project, containing a service
+// for each module that includes the plugin. Modules are built and launched from the root project.
+//
+// The Maven wrapper (mvnw) will be used where present.
+type MavenGenerator struct {
+	generatorBase
+	foundGroups   []*services.ServiceGroupConfig
+	foundServices []*services.ServiceConfig
+}
+
+// Name returns 'maven' to identify this generator
+func (v *MavenGenerator) Name() string {
+	return "maven"
+}
+
+type mavenPom struct {
+	ArtifactID string        `xml:"artifactId"`
+	Modules    []string      `xml:"modules>module"`
+	Plugins    []mavenPlugin `xml:"build>plugins>plugin"`
+}
+
+type mavenPlugin struct {
+	ArtifactID string `xml:"artifactId"`
+}
+
+func (p *mavenPom) isSpringBoot() bool {
+	for _, plugin := range p.Plugins {
+		if plugin.ArtifactID == "spring-boot-maven-plugin" {
+			return true
+		}
+	}
+	return false
+}
+
+// VisitDir checks a directory for a pom.xml file. If found, services will be generated for
+// the project and any of its modules that use the Spring Boot plugin.
+// Once a project has been found, true, filepath.SkipDir will be returned to ensure modules are not
+// visited again.
+func (v *MavenGenerator) VisitDir(path string) (bool, error) {
+	pom, err := readPom(path)
+	if err != nil || pom == nil {
+		return false, errors.WithStack(err)
+	}
+
+	relPath, err := filepath.Rel(v.basePath, path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	mvn := buildTool(path, "mvn", "mvnw")
+
+	if len(pom.Modules) == 0 {
+		if !pom.isSpringBoot() {
+			return false, nil
+		}
+		name := pom.ArtifactID
+		if name == "" {
+			name = filepath.Base(path)
+		}
+		launchChecks, err := springBootLaunchChecks(path)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		v.foundServices = append(v.foundServices, &services.ServiceConfig{
+			Name: name,
+			Path: &relPath,
+			Env:  []string{},
+			Commands: services.ServiceConfigCommands{
+				Build:  mvn + " package -DskipTests",
+				Launch: mvn + " spring-boot:run",
+			},
+			LaunchChecks: launchChecks,
+		})
+		return true, filepath.SkipDir
+	}
+
+	group := &services.ServiceGroupConfig{
+		Name: pom.ArtifactID,
+	}
+	if group.Name == "" {
+		group.Name = filepath.Base(path)
+	}
+	err = v.addModules(group, path, relPath, "", pom.Modules, mvn)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if len(group.Services) == 0 {
+		return false, nil
+	}
+	v.foundServices = append(v.foundServices, group.Services...)
+	v.foundGroups = append(v.foundGroups, group)
+	return true, filepath.SkipDir
+}
+
+// addModules adds services to group for each of the given modules, and their modules, that use
+// the Spring Boot plugin.
+func (v *MavenGenerator) addModules(group *services.ServiceGroupConfig, rootPath, relPath, parentModule string, modules []string, mvn string) error {
+	for _, module := range modules {
+		modulePath := filepath.Join(parentModule, module)
+		pom, err := readPom(filepath.Join(rootPath, modulePath))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if pom == nil {
+			continue
+		}
+		if len(pom.Modules) > 0 {
+			err = v.addModules(group, rootPath, relPath, modulePath, pom.Modules, mvn)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if !pom.isSpringBoot() {
+			continue
+		}
+
+		name := pom.ArtifactID
+		if name == "" {
+			name = jvmProjectName(modulePath)
+		}
+		launchChecks, err := springBootLaunchChecks(filepath.Join(rootPath, modulePath))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		servicePath := relPath
+		group.Services = append(group.Services, &services.ServiceConfig{
+			Name: name,
+			Path: &servicePath,
+			Env:  []string{},
+			Commands: services.ServiceConfigCommands{
+				Build:  mvn + " -pl " + filepath.ToSlash(modulePath) + " -am install -DskipTests",
+				Launch: mvn + " -pl " + filepath.ToSlash(modulePath) + " spring-boot:run",
+			},
+			LaunchChecks: launchChecks,
+		})
+	}
+	return nil
+}
+
+// readPom parses the pom.xml in path, returning nil if there is none.
+func readPom(path string) (*mavenPom, error) {
+	pomPath := filepath.Join(path, "pom.xml")
+	if _, err := os.Stat(pomPath); os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	data, err := ioutil.ReadFile(pomPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var pom mavenPom
+	err = xml.Unmarshal(data, &pom)
+	if err != nil {
+		return nil, errors.Wrap(err, pomPath)
+	}
+	return &pom, nil
+}
+
+// Groups returns a slice of groups generated on previous walks
+func (v *MavenGenerator) Groups() []*services.ServiceGroupConfig {
+	return v.foundGroups
+}
+
+// Services returns a slice of services generated on previous walks
+func (v *MavenGenerator) Services() []*services.ServiceConfig {
+	return v.foundServices
+}
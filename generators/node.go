@@ -0,0 +1,165 @@
+package generators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+// nodeSourceDirs lists directories conventionally containing the source for Node.js projects
+var nodeSourceDirs = []string{
+	"app",
+	"lib",
+	"pages",
+	"server",
+	"src",
+}
+
+// NodeGenerator generates services from Node.js projects.
+// Services are generated from a package.json with a 'start' or 'dev' script.
+//
+// The package manager is selected based on the lockfile present alongside package.json,
+// defaulting to npm if no lockfile is found.
+//
+// Dependencies are installed with the install command, the 'build' script is used to build
+// and the 'start' script (or 'dev' if there is no 'start') is used to launch.
+type NodeGenerator struct {
+	generatorBase
+	foundServices []*services.ServiceConfig
+}
+
+// Name returns 'node' to identify this generator
+func (v *NodeGenerator) Name() string {
+	return "node"
+}
+
+type packageJSON struct {
+	Name    string            `json:"name"`
+	Scripts map[string]string `json:"scripts"`
+}
+
+// VisitDir searches a directory for a package.json file, and will store a service if it
+// defines a script to launch the project. Returns true in the first return value if a service was found.
+// Dependency directories will be skipped.
+func (v *NodeGenerator) VisitDir(path string) (bool, error) {
+	if filepath.Base(path) == "node_modules" {
+		return false, filepath.SkipDir
+	}
+
+	packagePath := filepath.Join(path, "package.json")
+	if _, err := os.Stat(packagePath); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	data, err := ioutil.ReadFile(packagePath)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	var pkg packageJSON
+	err = json.Unmarshal(data, &pkg)
+	if err != nil {
+		return false, errors.Wrap(err, packagePath)
+	}
+
+	launchScript := "start"
+	if _, ok := pkg.Scripts[launchScript]; !ok {
+		launchScript = "dev"
+	}
+	if _, ok := pkg.Scripts[launchScript]; !ok {
+		return false, nil
+	}
+
+	relPath, err := filepath.Rel(v.basePath, path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	name := pkg.Name
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	// Drop the scope from scoped packages, such as @company/package
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	pm := detectPackageManager(path)
+	service := &services.ServiceConfig{
+		Name: name,
+		Path: &relPath,
+		Env:  []string{},
+		Commands: services.ServiceConfigCommands{
+			Install: pm.install,
+			Launch:  pm.run(launchScript),
+		},
+	}
+	if _, ok := pkg.Scripts["build"]; ok {
+		service.Commands.Build = pm.run("build")
+	}
+
+	err = service.SetWatch(nodeWatch(path, relPath))
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	v.foundServices = append(v.foundServices, service)
+	return true, nil
+}
+
+// Services returns the services generated during the last walk
+func (v *NodeGenerator) Services() []*services.ServiceConfig {
+	return v.foundServices
+}
+
+type packageManager struct {
+	name    string
+	install string
+}
+
+// run returns the command to run the named package script
+func (p packageManager) run(script string) string {
+	if script == "start" || p.name == "yarn" {
+		return p.name + " " + script
+	}
+	return p.name + " run " + script
+}
+
+// detectPackageManager selects a package manager based on the lockfile in a project directory
+func detectPackageManager(path string) packageManager {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(path, name))
+		return err == nil
+	}
+	switch {
+	case exists("yarn.lock"):
+		return packageManager{name: "yarn", install: "yarn install"}
+	case exists("pnpm-lock.yaml"):
+		return packageManager{name: "pnpm", install: "pnpm install"}
+	case exists("package-lock.json"), exists("npm-shrinkwrap.json"):
+		return packageManager{name: "npm", install: "npm ci"}
+	}
+	return packageManager{name: "npm", install: "npm install"}
+}
+
+// nodeWatch watches the conventional source directories for a project, or the whole project
+// if there are none. Dependencies are always excluded.
+func nodeWatch(path, relPath string) services.ServiceWatch {
+	var watch services.ServiceWatch
+	for _, dir := range nodeSourceDirs {
+		if info, err := os.Stat(filepath.Join(path, dir)); err == nil && info.IsDir() {
+			watch.IncludedPaths = append(watch.IncludedPaths, filepath.Join(relPath, dir))
+		}
+	}
+	if len(watch.IncludedPaths) == 0 {
+		watch.IncludedPaths = []string{relPath}
+	}
+	watch.ExcludedPaths = []string{filepath.Join(relPath, "node_modules")}
+	return watch
+}
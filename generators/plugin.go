@@ -0,0 +1,222 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+// PluginPrefix is the prefix for the names of executables providing generator plugins.
+// The remainder of the executable name is used as the name of the generator.
+const PluginPrefix = "nedward-generator-"
+
+// PluginGenerator generates services, groups and imports by running an external executable.
+//
+// The executable is run once for each directory visited, and is passed a PluginRequest as JSON
+// on stdin. It should write a PluginResponse as JSON to stdout and exit with status 0.
+// A non-zero exit status will halt generation, with anything written to stderr reported as the error.
+type PluginGenerator struct {
+	generatorBase
+	name string
+	path string
+
+	foundServices []*services.ServiceConfig
+	foundGroups   []PluginGroup
+	foundImports  []string
+}
+
+// PluginRequest is passed to a generator plugin for each directory visited
+type PluginRequest struct {
+	// The absolute path to the directory being visited
+	Path string `json:"path"`
+	// The path to the directory, relative to the root of the walk
+	RelPath string `json:"rel_path"`
+	// The absolute path to the root of the walk
+	BasePath string `json:"base_path"`
+}
+
+// PluginResponse is returned by a generator plugin for each directory visited.
+// Paths in services and imports should be relative to the root of the walk.
+type PluginResponse struct {
+	Services []*services.ServiceConfig `json:"services,omitempty"`
+	Groups   []PluginGroup             `json:"groups,omitempty"`
+	Imports  []string                  `json:"imports,omitempty"`
+	// Skip may be set to "dir" to prevent this plugin visiting subdirectories,
+	// or "all" to prevent any generator visiting subdirectories.
+	Skip string `json:"skip,omitempty"`
+}
+
+// PluginGroup defines a group returned by a generator plugin, in the same form as groups
+// in a config file. Children must be the names of services returned by the plugin.
+type PluginGroup struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Children    []string `json:"children"`
+	Env         []string `json:"env,omitempty"`
+}
+
+// NewPluginGenerator creates a generator that runs the plugin executable at path
+func NewPluginGenerator(path string) *PluginGenerator {
+	return &PluginGenerator{
+		name: strings.TrimPrefix(filepath.Base(path), PluginPrefix),
+		path: path,
+	}
+}
+
+// FindPlugins searches the given directories for generator plugin executables, returning a
+// generator for each. Where plugins with the same name are found in multiple directories,
+// the first will be used. Directories that do not exist are ignored.
+func FindPlugins(dirs ...string) ([]*PluginGenerator, error) {
+	var plugins []*PluginGenerator
+	var found = make(map[string]struct{})
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, file := range files {
+			name := file.Name()
+			if !strings.HasPrefix(name, PluginPrefix) || len(name) == len(PluginPrefix) {
+				continue
+			}
+			info, err := os.Stat(filepath.Join(dir, name))
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+			if _, exists := found[name]; exists {
+				continue
+			}
+			found[name] = struct{}{}
+			plugins = append(plugins, NewPluginGenerator(filepath.Join(dir, name)))
+		}
+	}
+	return plugins, nil
+}
+
+// Name returns the name of the plugin, without the 'nedward-generator-' prefix
+func (v *PluginGenerator) Name() string {
+	return v.name
+}
+
+// StartWalk lets the plugin know that a directory walk has been started, clearing
+// the results of any previous walk.
+func (v *PluginGenerator) StartWalk(basePath string) {
+	v.generatorBase.StartWalk(basePath)
+	v.foundServices = nil
+	v.foundGroups = nil
+	v.foundImports = nil
+}
+
+// VisitDir runs the plugin for a directory, storing any services, groups and imports
+// it returns. Returns true in the first return value if anything was found.
+func (v *PluginGenerator) VisitDir(path string) (bool, error) {
+	basePath, err := filepath.Abs(v.basePath)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	relPath, err := filepath.Rel(basePath, absPath)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	request, err := json.Marshal(PluginRequest{
+		Path:     absPath,
+		RelPath:  relPath,
+		BasePath: basePath,
+	})
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(v.path)
+	cmd.Dir = absPath
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return false, errors.Errorf("generator plugin %v failed in %v: %v: %v", v.name, relPath, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var response PluginResponse
+	if len(bytes.TrimSpace(stdout.Bytes())) > 0 {
+		err = json.Unmarshal(stdout.Bytes(), &response)
+		if err != nil {
+			return false, errors.Wrapf(err, "could not parse response from generator plugin %v in %v", v.name, relPath)
+		}
+	}
+
+	for _, service := range response.Services {
+		if service.Env == nil {
+			service.Env = []string{}
+		}
+	}
+	v.foundServices = append(v.foundServices, response.Services...)
+	v.foundGroups = append(v.foundGroups, response.Groups...)
+	v.foundImports = append(v.foundImports, response.Imports...)
+
+	found := len(response.Services) > 0 || len(response.Groups) > 0 || len(response.Imports) > 0
+	switch response.Skip {
+	case "":
+		return found, nil
+	case "dir":
+		return found, filepath.SkipDir
+	case "all":
+		return found, SkipAll
+	}
+	return found, errors.Errorf("generator plugin %v returned unknown skip value %q", v.name, response.Skip)
+}
+
+// Services returns a slice of services generated on previous walks
+func (v *PluginGenerator) Services() []*services.ServiceConfig {
+	return v.foundServices
+}
+
+// Groups returns a slice of groups generated on previous walks, with children resolved
+// against the services returned by the plugin. Children that cannot be resolved are omitted.
+func (v *PluginGenerator) Groups() []*services.ServiceGroupConfig {
+	var servicesByName = make(map[string]*services.ServiceConfig)
+	for _, service := range v.foundServices {
+		servicesByName[service.Name] = service
+	}
+
+	var groups []*services.ServiceGroupConfig
+	for _, pluginGroup := range v.foundGroups {
+		group := &services.ServiceGroupConfig{
+			Name:        pluginGroup.Name,
+			Aliases:     pluginGroup.Aliases,
+			Description: pluginGroup.Description,
+			Env:         pluginGroup.Env,
+		}
+		for _, child := range pluginGroup.Children {
+			if service, ok := servicesByName[child]; ok {
+				group.Services = append(group.Services, service)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// Imports returns all imports found during previous walks
+func (v *PluginGenerator) Imports() []string {
+	return v.foundImports
+}
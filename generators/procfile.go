@@ -4,19 +4,40 @@ import (
 	"bufio"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/nedscode/nedward/services"
 )
 
+// procfileNames lists the file names recognized as Procfiles, in order of preference
+var procfileNames = []string{
+	"Procfile.dev",
+	"Procfile",
+}
+
+var (
+	procfileLineExpr    = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s*(.*)$`)
+	procfileAssignExpr  = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(\S*)\s*`)
+	procfilePortVarExpr = regexp.MustCompile(`\$\{?([A-Za-z0-9_]*PORT)\}?`)
+	procfilePortArgExpr = regexp.MustCompile(`(?:^|\s)(?:--port[= ]|-p\s*)(\d+)\b`)
+)
+
 // ProcfileGenerator generates services and groups from Procfiles.
 //
 // For each Procfile, a group is generated to contain a set of services, one per
-// process in the Procfile.
+// process in the Procfile. A Procfile.dev will be used in preference to a Procfile
+// where both are present.
 //
 // The group is named for the directory containing the Procfile, services are named
-// using the form '[group]:[process]'.
+// using the form '[group]-[process]'.
+//
+// Variables from a .env file alongside the Procfile are added to the env for each service.
+// Ports for launch checks are taken from '--port' or '-p' arguments, and from variables named
+// PORT or ending in _PORT that are used in or assigned by the process command. The web process
+// is assumed to listen on PORT.
 type ProcfileGenerator struct {
 	generatorBase
 	foundGroups   []*services.ServiceGroupConfig
@@ -31,12 +52,9 @@ func (v *ProcfileGenerator) Name() string {
 // VisitDir searches a directory for a Procfile, generating services and groups for any
 // found. Returns true in the first return value if a Procfile was found.
 func (v *ProcfileGenerator) VisitDir(path string) (bool, error) {
-	procfilePath := filepath.Join(path, "Procfile")
-
-	if _, err := os.Stat(procfilePath); os.IsNotExist(err) {
+	procfilePath := findProcfile(path)
+	if procfilePath == "" {
 		return false, nil
-	} else if err != nil {
-		return false, errors.WithStack(err)
 	}
 
 	relPath, err := filepath.Rel(v.basePath, path)
@@ -44,7 +62,11 @@ func (v *ProcfileGenerator) VisitDir(path string) (bool, error) {
 		return false, errors.WithStack(err)
 	}
 
-	specFile, err := os.Open(procfilePath)
+	processes, err := readProcfile(procfilePath)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	env, err := readDotEnv(filepath.Join(path, ".env"))
 	if err != nil {
 		return false, errors.WithStack(err)
 	}
@@ -52,24 +74,22 @@ func (v *ProcfileGenerator) VisitDir(path string) (bool, error) {
 	group := &services.ServiceGroupConfig{
 		Name: filepath.Base(path),
 	}
-
-	scanner := bufio.NewScanner(specFile)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, ":") {
-			def := strings.SplitN(line, ":", 2)
-			service := &services.ServiceConfig{
-				Name: group.Name + "-" + def[0],
-				Path: &relPath,
-				Commands: services.ServiceConfigCommands{
-					Launch: strings.TrimSpace(def[1]),
-				},
+	for _, process := range processes {
+		serviceEnv := overrideEnv(env, process.env)
+		service := &services.ServiceConfig{
+			Name: group.Name + "-" + process.name,
+			Path: &relPath,
+			Env:  serviceEnv,
+			Commands: services.ServiceConfigCommands{
+				Launch: process.command,
+			},
+		}
+		if ports := procfilePorts(process, serviceEnv); len(ports) > 0 {
+			service.LaunchChecks = &services.LaunchChecks{
+				Ports: ports,
 			}
-			group.Services = append(group.Services, service)
 		}
-	}
-	if err := scanner.Err(); err != nil {
-		return false, errors.WithStack(err)
+		group.Services = append(group.Services, service)
 	}
 	v.foundServices = append(v.foundServices, group.Services...)
 	v.foundGroups = append(v.foundGroups, group)
@@ -85,3 +105,159 @@ func (v *ProcfileGenerator) Groups() []*services.ServiceGroupConfig {
 func (v *ProcfileGenerator) Services() []*services.ServiceConfig {
 	return v.foundServices
 }
+
+type procfileProcess struct {
+	name    string
+	command string
+	// Variables assigned at the start of the command, such as PORT=3000
+	env []string
+}
+
+// findProcfile returns the path to the preferred Procfile in path, or an empty string if there is none
+func findProcfile(path string) string {
+	for _, name := range procfileNames {
+		procfilePath := filepath.Join(path, name)
+		if info, err := os.Stat(procfilePath); err == nil && !info.IsDir() {
+			return procfilePath
+		}
+	}
+	return ""
+}
+
+// readProcfile returns the processes listed in a Procfile, in the order they appear.
+// Comments and blank lines are ignored. Variable assignments at the start of a command
+// are moved into the env for the process, since commands are not run in a shell.
+func readProcfile(procfilePath string) ([]procfileProcess, error) {
+	specFile, err := os.Open(procfilePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer specFile.Close()
+
+	var processes []procfileProcess
+	scanner := bufio.NewScanner(specFile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		def := procfileLineExpr.FindStringSubmatch(line)
+		if def == nil {
+			continue
+		}
+		process := procfileProcess{
+			name:    def[1],
+			command: strings.TrimSpace(def[2]),
+		}
+		for {
+			assignment := procfileAssignExpr.FindStringSubmatch(process.command)
+			if assignment == nil {
+				break
+			}
+			process.env = append(process.env, assignment[1]+"="+assignment[2])
+			process.command = process.command[len(assignment[0]):]
+		}
+		processes = append(processes, process)
+	}
+	return processes, errors.WithStack(scanner.Err())
+}
+
+// readDotEnv returns the variables set in a .env file, in the form KEY=VALUE.
+// Returns nil if the file does not exist.
+func readDotEnv(envPath string) ([]string, error) {
+	envFile, err := os.Open(envPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer envFile.Close()
+
+	var env []string
+	scanner := bufio.NewScanner(envFile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env = append(env, key+"="+value)
+	}
+	return env, errors.WithStack(scanner.Err())
+}
+
+// overrideEnv returns a copy of env, with the values in overrides replacing those with the same key
+func overrideEnv(env []string, overrides []string) []string {
+	var result = make([]string, 0, len(env)+len(overrides))
+	for _, entry := range env {
+		key := strings.SplitN(entry, "=", 2)[0]
+		var overridden bool
+		for _, override := range overrides {
+			if strings.HasPrefix(override, key+"=") {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			result = append(result, entry)
+		}
+	}
+	return append(result, overrides...)
+}
+
+// procfilePorts returns the ports a process is expected to listen on, based on its
+// command and env.
+func procfilePorts(process procfileProcess, env []string) []int {
+	var ports []int
+	addPort := func(value string) {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return
+		}
+		for _, existing := range ports {
+			if existing == port {
+				return
+			}
+		}
+		ports = append(ports, port)
+	}
+	lookup := func(key string) (string, bool) {
+		for _, entry := range env {
+			if strings.HasPrefix(entry, key+"=") {
+				return strings.TrimPrefix(entry, key+"="), true
+			}
+		}
+		return "", false
+	}
+
+	for _, match := range procfilePortArgExpr.FindAllStringSubmatch(process.command, -1) {
+		addPort(match[1])
+	}
+	for _, match := range procfilePortVarExpr.FindAllStringSubmatch(process.command, -1) {
+		if value, ok := lookup(match[1]); ok {
+			addPort(value)
+		}
+	}
+	for _, assignment := range process.env {
+		parts := strings.SplitN(assignment, "=", 2)
+		if parts[0] == "PORT" || strings.HasSuffix(parts[0], "_PORT") {
+			addPort(parts[1])
+		}
+	}
+	// By convention, the web process listens on PORT
+	if len(ports) == 0 && process.name == "web" {
+		if value, ok := lookup("PORT"); ok {
+			addPort(value)
+		}
+	}
+	return ports
+}
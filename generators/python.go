@@ -0,0 +1,180 @@
+package generators
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nedscode/nedward/home"
+	"github.com/nedscode/nedward/services"
+	"github.com/pelletier/go-toml"
+	"github.com/pkg/errors"
+)
+
+// pythonSkipDirs lists directories that contain dependencies or generated files, and will
+// not be searched for projects
+var pythonSkipDirs = map[string]bool{
+	".venv":       true,
+	"venv":        true,
+	"__pycache__": true,
+	".tox":        true,
+}
+
+// PythonGenerator generates services from Python projects.
+//
+// Services are generated for Django projects (identified by manage.py), projects declaring
+// a script entry point in pyproject.toml or Pipfile, and projects with a Procfile alongside
+// requirements.txt.
+//
+// Each service is given a virtualenv under the Nedward state directory. The install command
+// will create or refresh the virtualenv and install dependencies into it, and the bin directory
+// of the virtualenv is added to the PATH through the service env.
+type PythonGenerator struct {
+	generatorBase
+	foundServices []*services.ServiceConfig
+}
+
+// Name returns 'python' to identify this generator
+func (v *PythonGenerator) Name() string {
+	return "python"
+}
+
+// VisitDir checks a directory for a Python project, and will store a service if one is found
+// with a way to launch it. Returns true in the first return value if a service was found.
+// Virtualenvs and cache directories will be skipped.
+func (v *PythonGenerator) VisitDir(path string) (bool, error) {
+	if pythonSkipDirs[filepath.Base(path)] || fileExists(filepath.Join(path, "pyvenv.cfg")) {
+		return false, filepath.SkipDir
+	}
+
+	pyproject, err := readTOML(filepath.Join(path, "pyproject.toml"))
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	pipfile, err := readTOML(filepath.Join(path, "Pipfile"))
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	var launch string
+	var launchEnv []string
+	var launchChecks *services.LaunchChecks
+	switch {
+	case fileExists(filepath.Join(path, "manage.py")):
+		launch = "python manage.py runserver --noreload"
+		launchChecks = &services.LaunchChecks{
+			Ports: []int{8000},
+		}
+	case pyproject != nil && pythonScript(pyproject, "project.scripts", "tool.poetry.scripts") != "":
+		launch = pythonScript(pyproject, "project.scripts", "tool.poetry.scripts")
+	case pipfile != nil && pythonScript(pipfile, "scripts") != "":
+		launch = "pipenv run " + pythonScript(pipfile, "scripts")
+	case findProcfile(path) != "" && fileExists(filepath.Join(path, "requirements.txt")):
+		processes, err := readProcfile(findProcfile(path))
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		for _, process := range processes {
+			if launch == "" || process.name == "web" {
+				launch = process.command
+				launchEnv = process.env
+			}
+		}
+	}
+	if launch == "" {
+		return false, nil
+	}
+
+	relPath, err := filepath.Rel(v.basePath, path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	name := filepath.Base(path)
+	if pyproject != nil {
+		for _, key := range []string{"project.name", "tool.poetry.name"} {
+			if projectName, ok := pyproject.Get(key).(string); ok && projectName != "" {
+				name = projectName
+				break
+			}
+		}
+	}
+
+	service := &services.ServiceConfig{
+		Name: name,
+		Path: &relPath,
+		Env: append([]string{
+			"VIRTUAL_ENV=$" + home.VenvDirEnv + "/" + name,
+			"PATH=$VIRTUAL_ENV/bin:$PATH",
+		}, launchEnv...),
+		Commands: services.ServiceConfigCommands{
+			Install: pythonInstall(path, pyproject, pipfile),
+			Launch:  launch,
+		},
+		LaunchChecks: launchChecks,
+	}
+	err = service.SetWatch(services.ServiceWatch{
+		IncludedPaths: []string{relPath},
+		ExcludedPaths: []string{"**/__pycache__"},
+	})
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	v.foundServices = append(v.foundServices, service)
+	return true, nil
+}
+
+// Services returns the services generated during the last walk
+func (v *PythonGenerator) Services() []*services.ServiceConfig {
+	return v.foundServices
+}
+
+// pythonInstall returns a command to create or refresh the virtualenv for a project and install
+// its dependencies, using the tool appropriate to the files present.
+func pythonInstall(path string, pyproject, pipfile *toml.Tree) string {
+	install := "python3 -m venv $VIRTUAL_ENV"
+	switch {
+	case pipfile != nil:
+		install += " && pipenv install --dev"
+	case pyproject != nil && pyproject.Has("tool.poetry"):
+		install += " && poetry install"
+	case fileExists(filepath.Join(path, "requirements.txt")):
+		install += " && $VIRTUAL_ENV/bin/pip install -r requirements.txt"
+	case pyproject != nil:
+		install += " && $VIRTUAL_ENV/bin/pip install -e ."
+	default:
+		return install
+	}
+	return "sh -c \"" + install + "\""
+}
+
+// pythonScript returns the name of the first script declared in any of the given tables.
+func pythonScript(tree *toml.Tree, tables ...string) string {
+	for _, table := range tables {
+		scripts, ok := tree.Get(table).(*toml.Tree)
+		if !ok {
+			continue
+		}
+		keys := scripts.Keys()
+		if len(keys) > 0 {
+			sort.Strings(keys)
+			return keys[0]
+		}
+	}
+	return ""
+}
+
+// readTOML parses a TOML file, returning nil if it does not exist.
+func readTOML(tomlPath string) (*toml.Tree, error) {
+	if !fileExists(tomlPath) {
+		return nil, nil
+	}
+	tree, err := toml.LoadFile(tomlPath)
+	return tree, errors.Wrap(err, tomlPath)
+}
+
+func fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
+}
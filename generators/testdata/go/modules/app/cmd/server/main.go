@@ -0,0 +1,10 @@
+package main
+
+import (
+	"example.com/app/internal/handler"
+	"example.com/lib"
+)
+
+func main() {
+	handler.Handle(lib.Name())
+}
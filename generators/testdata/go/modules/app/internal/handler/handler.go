@@ -0,0 +1,8 @@
+package handler
+
+import "fmt"
+
+// Handle prints a name
+func Handle(name string) {
+	fmt.Println(name)
+}
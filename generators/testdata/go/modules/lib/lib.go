@@ -0,0 +1,6 @@
+package lib
+
+// Name returns the name of this library
+func Name() string {
+	return "lib"
+}
@@ -0,0 +1,4 @@
+plugins {
+    kotlin("jvm")
+    id("org.springframework.boot") version "3.2.0"
+}
@@ -0,0 +1,4 @@
+package com.example
+
+@SpringBootApplication
+class ApiApplication
@@ -0,0 +1,4 @@
+rootProject.name = "pipeline"
+
+include("api", "worker")
+include(":shared")
@@ -0,0 +1,5 @@
+package com.example.api;
+
+@SpringBootApplication(scanBasePackages = "com.example")
+public class ApiApplication {
+}
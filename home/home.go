@@ -16,8 +16,21 @@ type NedwardConfiguration struct {
 	PidDir       string
 	StateDir     string
 	ScriptDir    string
+	BinDir       string
+	VenvDir      string
+	PluginDir    string
+	// Path to the key used to encrypt secrets files
+	SecretKeyFile string
 }
 
+// BinDirEnv is the environment variable through which the location of BinDir
+// is made available to service commands.
+const BinDirEnv = "NEDWARD_BIN_DIR"
+
+// VenvDirEnv is the environment variable through which the location of VenvDir
+// is made available to service commands.
+const VenvDirEnv = "NEDWARD_VENV_DIR"
+
 // NedwardConfig stores a shared instance of NedwardConfiguration for use across the app
 var NedwardConfig = NedwardConfiguration{}
 
@@ -45,5 +58,16 @@ func (e *NedwardConfiguration) Initialize() error {
 	createDirIfNeeded(e.StateDir)
 	e.ScriptDir = path.Join(e.Dir, "scriptFiles")
 	createDirIfNeeded(e.ScriptDir)
-	return nil
+	e.BinDir = path.Join(e.StateDir, "bin")
+	createDirIfNeeded(e.BinDir)
+	e.VenvDir = path.Join(e.StateDir, "venv")
+	createDirIfNeeded(e.VenvDir)
+	e.PluginDir = path.Join(e.Dir, "plugins")
+	createDirIfNeeded(e.PluginDir)
+	e.SecretKeyFile = path.Join(e.Dir, "secrets.key")
+	err = os.Setenv(BinDirEnv, e.BinDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Setenv(VenvDirEnv, e.VenvDir))
 }
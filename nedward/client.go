@@ -42,6 +42,12 @@ type Client struct {
 	serviceMap map[string]*services.ServiceConfig
 
 	Tags []string // Tags to distinguish runners started by this instance of edward
+
+	Vars map[string]string // Values for config variables, overriding those in the config
+
+	Env []string // Environment variables set on the command line, taking precedence over those in the config
+
+	Profile string // Profile to apply to services and groups that define it
 }
 
 type TaskFollower interface {
@@ -105,6 +111,8 @@ func (c *Client) startAndTrack(sgs []services.ServiceOrGroup, skipBuild bool, ta
 		NoWatch:           noWatch,
 		Tags:              c.Tags,
 		LogFile:           c.LogFile,
+		Vars:              c.Vars,
+		Profile:           c.Profile,
 	}
 
 	task := tracker.NewTask(c.Follower.Handle)
@@ -124,12 +132,12 @@ func (c *Client) startAndTrack(sgs []services.ServiceOrGroup, skipBuild bool, ta
 	for _, s := range sgs {
 		if skipBuild {
 			c.Logger.Println("skipping build phase")
-			err = s.Launch(cfg, services.ContextOverride{}, task, p)
+			err = s.Launch(cfg, services.ContextOverride{Env: c.Env}, task, p)
 			if err != nil {
 				return errors.WithMessage(err, "Error launching "+s.GetName())
 			}
 		} else {
-			err = s.Start(cfg, services.ContextOverride{}, task, p)
+			err = s.Start(cfg, services.ContextOverride{Env: c.Env}, task, p)
 			if err != nil {
 				return errors.WithMessage(err, "Error starting "+s.GetName())
 			}
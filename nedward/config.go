@@ -1,8 +1,11 @@
 package nedward
 
 import (
+	"encoding/json"
+	"fmt"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/nedscode/nedward/config"
 	"github.com/nedscode/nedward/services"
@@ -13,7 +16,7 @@ import (
 func (c *Client) LoadConfig(nedwardVersion string) error {
 	if c.Config != "" {
 		c.basePath = filepath.Dir(c.Config)
-		cfg, err := config.LoadConfig(c.Config, nedwardVersion, c.Logger)
+		cfg, err := config.LoadConfigWithOptions(c.Config, nedwardVersion, c.Logger, c.loadOptions())
 		if err != nil {
 			return errors.WithMessage(err, c.Config)
 		}
@@ -26,6 +29,52 @@ func (c *Client) LoadConfig(nedwardVersion string) error {
 	return errors.New("No config file found")
 }
 
+// ValidateConfig checks the config file at configPath and the files it imports, printing each
+// problem found. Returns an error if there were any problems.
+func (c *Client) ValidateConfig(configPath string) error {
+	if configPath == "" {
+		return errors.New("No config file found")
+	}
+	problems, err := config.Validate(configPath, c.loadOptions())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, problem := range problems {
+		if rel, err := filepath.Rel(c.WorkingDir, problem.File); err == nil && !strings.HasPrefix(rel, "..") {
+			problem.File = rel
+		}
+		fmt.Fprintln(c.Output, problem)
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("found %v problem(s) in config", len(problems))
+	}
+	fmt.Fprintln(c.Output, "No problems found in", configPath)
+	return nil
+}
+
+// ShowConfig prints the resolved config for the named services and groups, or for all services
+// and groups if no names are given.
+func (c *Client) ShowConfig(names []string) error {
+	if c.Config == "" {
+		return errors.New("No config file found")
+	}
+	cfg, err := config.LoadConfigWithOptions(c.Config, "", c.Logger, c.loadOptions())
+	if err != nil {
+		return errors.WithMessage(err, c.Config)
+	}
+	return errors.WithStack(cfg.Show(c.Output, names...))
+}
+
+// ConfigSchema prints a JSON Schema describing config files
+func (c *Client) ConfigSchema() error {
+	content, err := json.MarshalIndent(config.Schema(), "", "    ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Fprintln(c.Output, string(content))
+	return nil
+}
+
 // getServicesOrGroups returns services and groups matching any of the provided names
 func (c *Client) getServicesOrGroups(names []string) ([]services.ServiceOrGroup, error) {
 	var outSG []services.ServiceOrGroup
@@ -41,24 +90,14 @@ func (c *Client) getServicesOrGroups(names []string) ([]services.ServiceOrGroup,
 
 // getServiceOrGroup returns the service/group matching the provided name
 func (c *Client) getServiceOrGroup(name string) (services.ServiceOrGroup, error) {
-	if group, ok := c.groupMap[name]; ok {
-		return group, nil
+	sg, err := services.FindServiceOrGroup(name, c.serviceMap, c.groupMap)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
-	if service, ok := c.serviceMap[name]; ok {
-		return service, nil
-	}
-	// Check aliases
-	for _, group := range c.groupMap {
-		if group.Matches(name) {
-			return group, nil
-		}
+	if sg == nil {
+		return nil, errors.New("Service or group not found")
 	}
-	for _, service := range c.serviceMap {
-		if service.Matches(name) {
-			return service, nil
-		}
-	}
-	return nil, errors.New("Service or group not found")
+	return sg, nil
 }
 
 // getAllGroupsSorted returns a slice of all groups, sorted by name
@@ -92,3 +131,8 @@ func (s serviceOrGroupByName) Swap(i, j int) {
 func (s serviceOrGroupByName) Less(i, j int) bool {
 	return s[i].GetName() < s[j].GetName()
 }
+
+// loadOptions returns the settings for loading config that were given on the command line
+func (c *Client) loadOptions() config.LoadOptions {
+	return config.LoadOptions{Vars: c.Vars, Profile: c.Profile}
+}
@@ -0,0 +1,110 @@
+package nedward
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kylelemons/godebug/diff"
+)
+
+// diffContext is the number of unchanged lines shown around each change in a diff
+const diffContext = 3
+
+type diffLine struct {
+	op   byte
+	text string
+	// Line indexes in the old and new content, before this line is applied
+	oldIndex int
+	newIndex int
+}
+
+// unifiedDiff returns a unified diff of the changes required to make oldContent into newContent,
+// with name used as the file name in the diff header. Returns an empty string if the content is identical.
+func unifiedDiff(name, oldContent, newContent string) string {
+	var lines []diffLine
+	var oldIndex, newIndex int
+	add := func(op byte, text string) {
+		lines = append(lines, diffLine{op: op, text: text, oldIndex: oldIndex, newIndex: newIndex})
+		if op != '+' {
+			oldIndex++
+		}
+		if op != '-' {
+			newIndex++
+		}
+	}
+	for _, chunk := range diff.DiffChunks(splitLines(oldContent), splitLines(newContent)) {
+		for _, line := range chunk.Deleted {
+			add('-', line)
+		}
+		for _, line := range chunk.Added {
+			add('+', line)
+		}
+		for _, line := range chunk.Equal {
+			add(' ', line)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	for start := 0; start < len(lines); {
+		// Find the next change, and extend the hunk until there is a gap larger than the context on both sides
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		last := first
+		for i := first; i < len(lines) && i-last <= 2*diffContext; i++ {
+			if lines[i].op != ' ' {
+				last = i
+			}
+		}
+		from := first - diffContext
+		if from < 0 {
+			from = 0
+		}
+		to := last + diffContext + 1
+		if to > len(lines) {
+			to = len(lines)
+		}
+
+		if buf.Len() == 0 {
+			fmt.Fprintf(buf, "--- %v\n+++ %v\n", name, name)
+		}
+		var oldCount, newCount int
+		for _, line := range lines[from:to] {
+			if line.op != '+' {
+				oldCount++
+			}
+			if line.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(buf, "@@ -%v +%v @@\n", hunkRange(lines[from].oldIndex, oldCount), hunkRange(lines[from].newIndex, newCount))
+		for _, line := range lines[from:to] {
+			fmt.Fprintf(buf, "%c%v\n", line.op, line.text)
+		}
+		start = to
+	}
+	return buf.String()
+}
+
+// hunkRange formats the start and length of a hunk, with 1-based line numbers
+func hunkRange(index, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%v,0", index)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%v", index+1)
+	}
+	return fmt.Sprintf("%v,%v", index+1, count)
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
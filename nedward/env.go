@@ -0,0 +1,48 @@
+package nedward
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+// ShowEnv prints the effective environment for the named service, with the source of each variable.
+// If the service is running, env from the groups under which it was started is included.
+// Variables inherited from the os environment are only included if all is true.
+func (c *Client) ShowEnv(name string, all bool) error {
+	sg, err := c.getServiceOrGroup(name)
+	if err != nil {
+		return errors.WithMessage(err, name)
+	}
+	service, ok := sg.(*services.ServiceConfig)
+	if !ok {
+		return errors.Errorf("%v is a group, env can only be shown for a service", name)
+	}
+	command, err := service.GetCommand(services.ContextOverride{Env: c.Env})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = command.ResolveEnv(); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, v := range command.Environment(all) {
+		source := v.Source
+		if v.File != "" {
+			source = fmt.Sprintf("%v, %v", source, c.relativePath(v.File))
+		}
+		// Values of secrets, and of any variables that include them, are not displayed
+		fmt.Fprintf(c.Output, "%v=%v (%v)\n", v.Key, command.Redact(v.Value), source)
+	}
+	return nil
+}
+
+// relativePath returns path relative to the directory containing the config file, where possible
+func (c *Client) relativePath(path string) string {
+	if rel, err := filepath.Rel(c.basePath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,62 @@
+package nedward_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"path"
+	"testing"
+
+	"github.com/nedscode/nedward/common"
+	"github.com/nedscode/nedward/nedward"
+	"github.com/theothertomelliott/must"
+)
+
+func TestShowEnv(t *testing.T) {
+	var tests = []struct {
+		name     string
+		service  string
+		env      []string
+		expected string
+		err      error
+	}{
+		{
+			name:    "service",
+			service: "service",
+			expected: `LEVEL=service (service)
+REGION=local (global)
+SECRET=from-file (service, service.env)
+`,
+		},
+		{
+			name:    "command line",
+			service: "service",
+			env:     []string{"LEVEL=cli"},
+			expected: `LEVEL=cli (command line)
+REGION=local (global)
+SECRET=from-file (service, service.env)
+`,
+		},
+		{
+			name:    "group",
+			service: "group",
+			err:     errors.New("group is a group, env can only be shown for a service"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wd, cleanup := createWorkingDir(t, test.name, "testdata/env")
+			defer cleanup()
+
+			client, err := nedward.NewClientWithConfig(path.Join(wd, "nedward.json"), common.NedwardVersion, log.New(&bytes.Buffer{}, "", 0))
+			must.BeNoError(t, err)
+			var out bytes.Buffer
+			client.Output = &out
+			client.Env = test.env
+
+			err = client.ShowEnv(test.service, false)
+			must.BeEqualErrors(t, test.err, err)
+			must.BeEqual(t, test.expected, out.String())
+		})
+	}
+}
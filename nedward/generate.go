@@ -1,8 +1,9 @@
 package nedward
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,11 +11,19 @@ import (
 	"github.com/nedscode/nedward/common"
 	"github.com/nedscode/nedward/config"
 	"github.com/nedscode/nedward/generators"
+	"github.com/nedscode/nedward/home"
 	"github.com/nedscode/nedward/services"
 	"github.com/pkg/errors"
 )
 
-func (c *Client) Generate(names []string, force bool, group string, targets []string) error {
+// Generate searches the working directory for projects, and adds services, groups and imports
+// for any found to the config file.
+// If output is set, the updated config will be written to that file instead of the config file.
+// If merge is set, the commands, launch check ports and watch settings of existing services will be
+// updated from those generated, and each changed field will be reported.
+// If output is set, the updated config will be written to that file instead of the config file.
+// If dryRun is set, nothing will be written, and a diff of the changes to the config file will be shown.
+func (c *Client) Generate(names []string, force bool, group string, targets []string, merge bool, dryRun bool, output string) error {
 	var cfg config.Config
 	configPath := c.Config
 	if configPath == "" {
@@ -65,9 +74,23 @@ func (c *Client) Generate(names []string, force bool, group string, targets []st
 	if err != nil {
 		return errors.WithStack(err)
 	}
+
+	var changes []config.ServiceChange
+	if merge {
+		changes, err = cfg.MergeServices(foundServices)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	if len(filteredServices) == 0 &&
 		len(filteredGroups) == 0 &&
-		len(filteredImports) == 0 {
+		len(filteredImports) == 0 &&
+		len(changes) == 0 {
+		if merge {
+			fmt.Fprintln(c.Output, "No new or changed services, groups or imports found")
+			return nil
+		}
 		fmt.Fprintln(c.Output, "No new services, groups or imports found")
 		return nil
 	}
@@ -79,11 +102,13 @@ func (c *Client) Generate(names []string, force bool, group string, targets []st
 	}
 
 	// Prompt user to confirm the list of services that will be generated
-	if !force {
-		confirmed, err := c.confirmList(&cfg, filteredServices, filteredGroups, filteredImports)
+	if !force && !dryRun {
+		confirmed, err := c.confirmList(&cfg, filteredServices, filteredGroups, filteredImports, changes)
 		if !confirmed {
 			return errors.WithStack(err)
 		}
+	} else {
+		c.printChanges(changes)
 	}
 
 	foundServices, err = cfg.NormalizeServicePaths(c.WorkingDir, foundServices)
@@ -122,25 +147,47 @@ func (c *Client) Generate(names []string, force bool, group string, targets []st
 		cfg.AppendGroups([]*services.ServiceGroupConfig{newGroupConfig})
 	}
 
-	cfg.Imports = append(cfg.Imports, foundImports...)
+	for _, i := range foundImports {
+		cfg.Imports = append(cfg.Imports, config.Import{Path: i})
+	}
 
-	f, err := os.Create(configPath)
+	var content bytes.Buffer
+	err = cfg.Save(&content)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	defer f.Close()
 
-	w := bufio.NewWriter(f)
-	err = cfg.Save(w)
-	if err != nil {
-		return errors.WithStack(err)
+	if dryRun {
+		current, err := ioutil.ReadFile(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+		name, err := filepath.Rel(c.WorkingDir, configPath)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		diff := unifiedDiff(name, string(current), content.String())
+		if diff == "" {
+			fmt.Fprintln(c.Output, "No changes to", configPath)
+			return nil
+		}
+		fmt.Fprint(c.Output, diff)
+		return nil
+	}
+
+	outputPath := configPath
+	if output != "" {
+		outputPath = output
+		if !filepath.IsAbs(outputPath) {
+			outputPath = filepath.Join(c.WorkingDir, outputPath)
+		}
 	}
-	err = w.Flush()
+	err = ioutil.WriteFile(outputPath, content.Bytes(), 0644)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	fmt.Fprintln(c.Output, "Wrote to:", configPath)
+	fmt.Fprintln(c.Output, "Wrote to:", outputPath)
 
 	return nil
 }
@@ -181,7 +228,7 @@ func (c *Client) filterGenerated(cfg *config.Config,
 	for _, i := range foundImports {
 		var found bool
 		for _, existingImport := range cfg.Imports {
-			if existingImport == i {
+			if existingImport.Path == i {
 				found = true
 			}
 		}
@@ -195,7 +242,8 @@ func (c *Client) filterGenerated(cfg *config.Config,
 func (c *Client) confirmList(cfg *config.Config,
 	filteredServices []string,
 	filteredGroups []string,
-	filteredImports []string) (bool, error) {
+	filteredImports []string,
+	changes []config.ServiceChange) (bool, error) {
 
 	fmt.Fprintln(c.Output, "The following will be generated:")
 	if len(filteredServices) > 0 {
@@ -216,6 +264,7 @@ func (c *Client) confirmList(cfg *config.Config,
 	for _, i := range filteredImports {
 		fmt.Fprintf(c.Output, "\t%v\n", i)
 	}
+	c.printChanges(changes)
 
 	if !c.askForConfirmation("Do you wish to continue?") {
 		return false, nil
@@ -224,13 +273,47 @@ func (c *Client) confirmList(cfg *config.Config,
 	return true, nil
 }
 
+// printChanges lists changes made to existing services
+func (c *Client) printChanges(changes []config.ServiceChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintln(c.Output, "Updates:")
+	for _, change := range changes {
+		fmt.Fprintf(c.Output, "\t%v\n", change)
+	}
+}
+
 func generatorsMatchingTargets(targets []string) ([]generators.Generator, error) {
 	allGenerators := []generators.Generator{
 		&generators.NedwardGenerator{},
 		&generators.DockerGenerator{},
+		&generators.ComposeGenerator{},
 		&generators.GoGenerator{},
+		&generators.NodeGenerator{},
+		&generators.MavenGenerator{},
+		&generators.GradleGenerator{},
+		&generators.PythonGenerator{},
+		&generators.MakeGenerator{},
 		&generators.IcbmGenerator{},
 	}
+
+	// Plugins in the Nedward home dir take precedence over those on the PATH,
+	// and built-in generators take precedence over both.
+	plugins, err := generators.FindPlugins(append([]string{home.NedwardConfig.PluginDir}, filepath.SplitList(os.Getenv("PATH"))...)...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	builtIn := make(map[string]struct{})
+	for _, gen := range allGenerators {
+		builtIn[gen.Name()] = struct{}{}
+	}
+	for _, plugin := range plugins {
+		if _, exists := builtIn[plugin.Name()]; !exists {
+			allGenerators = append(allGenerators, plugin)
+		}
+	}
+
 	if len(targets) == 0 {
 		return allGenerators, nil
 	}
@@ -31,10 +31,14 @@ func TestGenerate(t *testing.T) {
 		group            string
 		targets          []string
 		force            bool
+		merge            bool
+		dryRun           bool
+		output           string
 		input            string
 		expectedOutput   string
 		expectedServices []string
 		expectedGroups   map[string][]string
+		expectedLaunch   map[string]string
 		err              error
 	}{
 		{
@@ -120,6 +124,68 @@ Do you wish to continue? [y/n]? Wrote to: ${TMP_PATH}/nedward.json
 			expectedServices: []string{"nedward-test-service", "nedward-test-service2"},
 			expectedGroups:   map[string][]string{"group1": []string{"nedward-test-service", "nedward-test-service2"}},
 		},
+		{
+			name:   "existing config and services - merged",
+			path:   "testdata/generate/singlewithconfig",
+			config: "nedward.json",
+			merge:  true,
+			input:  "Y\n",
+			expectedOutput: `The following will be generated:
+Updates:
+	nedward-test-service: commands.build changed from "go build" to "go install"
+	nedward-test-service: commands.launch changed from "./nedward-test-service" to "nedward-test-service"
+Do you wish to continue? [y/n]? Wrote to: ${TMP_PATH}/nedward.json
+`,
+			expectedServices: []string{"nedward-test-service"},
+			expectedLaunch:   map[string]string{"nedward-test-service": "nedward-test-service"},
+		},
+		{
+			name:   "dry run shows diff without writing",
+			path:   "testdata/generate/groupwithconfig",
+			config: "nedward.json",
+			dryRun: true,
+			expectedOutput: `--- nedward.json
++++ nedward.json
+@@ -2,7 +2,9 @@
+     "groups": [
+         {
+             "name": "group1",
+-            "children": ["nedward-test-service"]
++            "children": [
++                "nedward-test-service"
++            ]
+         }
+     ],
+     "services": [
+@@ -13,6 +15,14 @@
+                 "build": "go build",
+                 "launch": "./nedward-test-service"
+             }
++        },
++        {
++            "name": "nedward-test-service2",
++            "path": "nedward-test-service2",
++            "commands": {
++                "build": "go install",
++                "launch": "nedward-test-service2"
++            }
+         }
+     ]
+ }
+`,
+			expectedServices: []string{"nedward-test-service"},
+			expectedGroups:   map[string][]string{"group1": []string{"nedward-test-service"}},
+		},
+		{
+			name:   "output to another file",
+			path:   "testdata/generate/groupwithconfig",
+			config: "nedward.json",
+			force:  true,
+			output: "generated.json",
+			expectedOutput: `Wrote to: ${TMP_PATH}/generated.json
+`,
+			expectedServices: []string{"nedward-test-service", "nedward-test-service2"},
+		},
 	}
 	for _, test := range tests {
 		test := test
@@ -167,7 +233,7 @@ Do you wish to continue? [y/n]? Wrote to: ${TMP_PATH}/nedward.json
 				ioWg.Done()
 			}()
 
-			err = client.Generate(test.services, test.force, test.group, test.targets)
+			err = client.Generate(test.services, test.force, test.group, test.targets, test.merge, test.dryRun, test.output)
 			inputWriter.Close()
 			outputWriter.Close()
 			must.BeEqualErrors(t, test.err, err)
@@ -180,7 +246,11 @@ Do you wish to continue? [y/n]? Wrote to: ${TMP_PATH}/nedward.json
 			expectedOutput := strings.Replace(test.expectedOutput, "${TMP_PATH}", wd, 1)
 			must.BeEqual(t, expectedOutput, output)
 
-			cfg, err := config.LoadConfig(filepath.Join(client.WorkingDir, test.config), common.NedwardVersion, client.Logger)
+			configPath := test.config
+			if test.output != "" {
+				configPath = test.output
+			}
+			cfg, err := config.LoadConfig(filepath.Join(client.WorkingDir, configPath), common.NedwardVersion, client.Logger)
 			if err != nil {
 				t.Error(err)
 				return
@@ -198,6 +268,9 @@ Do you wish to continue? [y/n]? Wrote to: ${TMP_PATH}/nedward.json
 			sort.Strings(groups)
 
 			must.BeEqual(t, test.expectedServices, services)
+			for serviceName, expectedLaunch := range test.expectedLaunch {
+				must.BeEqual(t, expectedLaunch, cfg.ServiceMap[serviceName].Commands.Launch, fmt.Sprintf("Launch command for '%s' did not match\n", serviceName))
+			}
 			for groupName, expectedChildren := range test.expectedGroups {
 				if group, ok := cfg.GroupMap[groupName]; ok {
 					var children []string
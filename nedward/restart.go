@@ -72,6 +72,8 @@ func (c *Client) restartOneOrMoreServices(serviceNames []string, skipBuild bool,
 		NoWatch:           noWatch,
 		Tags:              c.Tags,
 		LogFile:           c.LogFile,
+		Vars:              c.Vars,
+		Profile:           c.Profile,
 	}
 
 	task := tracker.NewTask(c.Follower.Handle)
@@ -89,7 +91,7 @@ func (c *Client) restartOneOrMoreServices(serviceNames []string, skipBuild bool,
 		_ = <-launchPool.Complete()
 	}()
 	for _, s := range sgs {
-		err = s.Restart(cfg, services.ContextOverride{}, task, launchPool)
+		err = s.Restart(cfg, services.ContextOverride{Env: c.Env}, task, launchPool)
 		if err != nil {
 			return errors.WithStack(err)
 		}
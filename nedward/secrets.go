@@ -0,0 +1,46 @@
+package nedward
+
+import (
+	"fmt"
+
+	"github.com/nedscode/nedward/home"
+	"github.com/nedscode/nedward/secrets"
+	"github.com/nedscode/nedward/services"
+	"github.com/pkg/errors"
+)
+
+// SetSecret stores a value in the encrypted secrets file alongside the config file, so it may
+// be referenced from env with from_secret. The key is created on first use.
+func (c *Client) SetSecret(name, value string) error {
+	if name == "" {
+		return errors.New("a name is required")
+	}
+	store, err := c.loadSecrets()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	store.Set(name, value)
+	return errors.WithStack(store.Save())
+}
+
+// GetSecret prints a value from the encrypted secrets file alongside the config file
+func (c *Client) GetSecret(name string) error {
+	store, err := c.loadSecrets()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	value, ok := store.Get(name)
+	if !ok {
+		return errors.Errorf("secret %v not found", name)
+	}
+	fmt.Fprintln(c.Output, value)
+	return nil
+}
+
+func (c *Client) loadSecrets() (*secrets.Store, error) {
+	if c.Config == "" {
+		return nil, errors.New("No config file found")
+	}
+	store, err := secrets.Load(services.SecretsPath(c.Config), home.NedwardConfig.SecretKeyFile)
+	return store, errors.WithStack(err)
+}
@@ -44,43 +44,59 @@ func (c *Client) Status(names []string, all bool) (string, error) {
 	if all {
 		headings = append(headings, "Config")
 	}
-	table.SetHeader(headings)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
 
+	var statuses []statusCommandTuple
+	showProfile := false
 	for _, s := range sgs {
-		statuses, err := c.getStates(s)
+		serviceStatuses, err := c.getStates(s)
 		if err != nil {
 			return "", errors.WithStack(err)
 		}
-		for _, status := range statuses {
-			if status.status.MemoryInfo == nil {
-				status.status.MemoryInfo = &process.MemoryInfoStat{}
-			}
-			row := []string{
-				strconv.Itoa(status.command.Pid),
-				status.command.Service.Name,
-				string(status.status.State),
-				strings.Join(status.status.Ports, ","),
-				strconv.Itoa(status.status.StdoutLines) + " lines",
-				strconv.Itoa(status.status.StderrLines) + " lines",
-				humanize.Bytes(status.status.MemoryInfo.RSS),
-				humanize.Bytes(status.status.MemoryInfo.VMS),
-				humanize.Bytes(status.status.MemoryInfo.Swap),
-				status.status.StartTime.Format("2006-01-02 15:04:05"),
+		for _, status := range serviceStatuses {
+			if status.command.Profile != "" {
+				showProfile = true
 			}
-			if all {
-				configPath := status.command.Service.ConfigFile
-				wd, err := os.Getwd()
-				if err == nil {
-					relativePath, err := filepath.Rel(wd, configPath)
-					if err == nil && len(configPath) > len(relativePath) {
-						configPath = relativePath
-					}
+		}
+		statuses = append(statuses, serviceStatuses...)
+	}
+	// Profiles are only shown if any service was started with one
+	if showProfile {
+		headings = append(headings, "Profile")
+	}
+	table.SetHeader(headings)
+
+	for _, status := range statuses {
+		if status.status.MemoryInfo == nil {
+			status.status.MemoryInfo = &process.MemoryInfoStat{}
+		}
+		row := []string{
+			strconv.Itoa(status.command.Pid),
+			status.command.Service.Name,
+			string(status.status.State),
+			strings.Join(status.status.Ports, ","),
+			strconv.Itoa(status.status.StdoutLines) + " lines",
+			strconv.Itoa(status.status.StderrLines) + " lines",
+			humanize.Bytes(status.status.MemoryInfo.RSS),
+			humanize.Bytes(status.status.MemoryInfo.VMS),
+			humanize.Bytes(status.status.MemoryInfo.Swap),
+			status.status.StartTime.Format("2006-01-02 15:04:05"),
+		}
+		if all {
+			configPath := status.command.Service.ConfigFile
+			wd, err := os.Getwd()
+			if err == nil {
+				relativePath, err := filepath.Rel(wd, configPath)
+				if err == nil && len(configPath) > len(relativePath) {
+					configPath = relativePath
 				}
-				row = append(row, configPath)
 			}
-			table.Append(row)
+			row = append(row, configPath)
+		}
+		if showProfile {
+			row = append(row, status.command.Profile)
 		}
+		table.Append(row)
 	}
 	table.Render()
 	return buf.String(), nil
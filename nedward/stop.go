@@ -28,6 +28,8 @@ func (c *Client) Stop(names []string, force bool, exclude []string, all bool) er
 		Exclusions:        exclude,
 		Tags:              c.Tags,
 		LogFile:           c.LogFile,
+		Vars:              c.Vars,
+		Profile:           c.Profile,
 	}
 
 	task := tracker.NewTask(c.Follower.Handle)
@@ -48,7 +50,7 @@ func (c *Client) Stop(names []string, force bool, exclude []string, all bool) er
 	for _, s := range sgs {
 		states, err := c.getStates(s)
 		if len(states) != 0 && err == nil {
-			_ = s.Stop(cfg, services.ContextOverride{}, task, p)
+			_ = s.Stop(cfg, services.ContextOverride{Env: c.Env}, task, p)
 		}
 	}
 	return nil
@@ -70,7 +70,7 @@ func followGroupLog(group *services.ServiceGroupConfig, logChannel chan runner.L
 
 func followServiceLog(service *services.ServiceConfig, logChannel chan runner.LogLine) ([]runner.LogLine, error) {
 	// Skip services that don't have a launch command
-	if service.Commands.Launch == "" {
+	if !service.IsLaunchable() {
 		return nil, nil
 	}
 
@@ -116,6 +116,17 @@ func (r *Runner) updateStatusDetail() {
 	r.status.StdoutLines = r.standardLog.Len()
 	r.status.StderrLines = r.errorLog.Len()
 
+	if r.Service.IsDocker() {
+		state, err := r.Service.InspectContainer()
+		if err != nil {
+			r.Messagef("could not inspect container: %v", err)
+			return
+		}
+		r.status.Ports = state.Ports
+		r.saveStatus()
+		return
+	}
+
 	pid := r.command.Pid()
 	if pid != 0 {
 		proc, err := process.NewProcess(int32(pid))
@@ -136,6 +147,10 @@ func (r *Runner) updateStatusDetail() {
 		r.status.Ports = ports
 	}
 
+	r.saveStatus()
+}
+
+func (r *Runner) saveStatus() {
 	dir := home.NedwardConfig.StateDir
 	err := instance.SaveStatusForService(r.Service, r.instanceId, r.status, dir)
 	if err != nil {
@@ -322,6 +337,10 @@ func (r *Runner) startService() error {
 		stream: "stderr",
 	}
 
+	if r.Service.IsDocker() {
+		return errors.WithStack(r.startContainer())
+	}
+
 	command, cmdArgs, err := commandline.ParseCommand(os.ExpandEnv(r.Service.Commands.Launch))
 	if err != nil {
 		return errors.WithStack(err)
@@ -339,3 +358,38 @@ func (r *Runner) startService() error {
 
 	return nil
 }
+
+// startContainer runs the container for a docker service in the background, and follows
+// its logs for as long as it is running.
+func (r *Runner) startContainer() error {
+	command, err := r.Service.GetCommand(services.ContextOverride{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = command.ResolveEnv(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Clear out any container left over from a previous run
+	_ = r.Service.RemoveContainer()
+
+	runArgs, err := r.Service.DockerRunArgs(command.Env())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	out, err := exec.Command("docker", runArgs...).CombinedOutput()
+	if err != nil {
+		r.errorLog.Printf("%v", command.Redact(string(out)))
+		return errors.WithMessage(err, "docker run")
+	}
+
+	cmd := r.Service.DockerLogsCommand()
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Stdout = r.standardLog
+	cmd.Stderr = r.errorLog
+
+	r.command = NewRunningCommand(r.Service, cmd, &r.commandWait)
+	r.command.Start(r.errorLog)
+
+	return nil
+}
@@ -38,15 +38,23 @@ func (c *RunningCommand) Start(errorLog Logger) {
 	}()
 }
 
-// Interrupt sends an interrupt to a running command
+// Interrupt sends an interrupt to a running command.
+// For docker services, the container is stopped.
 func (c *RunningCommand) Interrupt() error {
+	if c.service.IsDocker() {
+		return errors.WithStack(c.service.StopContainer())
+	}
 	return errors.WithStack(
 		services.InterruptGroup(services.OperationConfig{}, c.command.Process.Pid, c.service),
 	)
 }
 
-// Kill sends a kill signal to a running command
+// Kill sends a kill signal to a running command.
+// For docker services, the container is killed.
 func (c *RunningCommand) Kill() error {
+	if c.service.IsDocker() {
+		return errors.WithStack(c.service.KillContainer())
+	}
 	return errors.WithStack(
 		services.KillGroup(services.OperationConfig{}, c.command.Process.Pid, c.service),
 	)
@@ -3,7 +3,6 @@ package runner
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/nedscode/nedward/services"
@@ -49,16 +48,8 @@ func startWatch(watch *services.ServiceWatch, restart func() error, logger Logge
 	go func() {
 		for event := range watcher.Events {
 			if event.Op == fsnotify.Write {
-				var wasExcluded bool
-				for _, excluded := range watch.ExcludedPaths {
-					if strings.HasPrefix(event.Name, excluded) {
-						logger.Printf("File is under excluded path: %v\n", excluded)
-						wasExcluded = true
-						break
-					}
-				}
-
-				if wasExcluded {
+				if excluded, wasExcluded := watch.Excludes(event.Name); wasExcluded {
+					logger.Printf("File is under excluded path: %v\n", excluded)
 					continue
 				}
 				fmt.Printf("Rebuilding %v\n", watch.Service.GetName())
@@ -0,0 +1,145 @@
+// Package secrets provides an encrypted store for values that should not be kept in plain text
+// in config files.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// FileName is the name of the secrets file, stored in the same directory as the config file
+const FileName = "nedward.secrets"
+
+// keySize is the size of the key used to encrypt secrets files, selecting AES-256
+const keySize = 32
+
+// Store holds a set of named secrets, which are encrypted when saved to a file
+type Store struct {
+	path    string
+	keyPath string
+	values  map[string]string
+}
+
+// Load reads the secrets file at path, decrypting it with the key at keyPath.
+// If the file does not exist, an empty store is returned.
+func Load(path, keyPath string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		keyPath: keyPath,
+		values:  make(map[string]string),
+	}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	key, err := ioutil.ReadFile(keyPath)
+	if os.IsNotExist(err) {
+		return nil, errors.Errorf("could not decrypt %v: no key found at %v", path, keyPath)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, errors.WithMessage(err, keyPath)
+	}
+	if len(content) < gcm.NonceSize() {
+		return nil, errors.Errorf("could not decrypt %v: file is too short", path)
+	}
+	nonce, ciphertext := content[:gcm.NonceSize()], content[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.Errorf("could not decrypt %v: the file is corrupt or was encrypted with a different key", path)
+	}
+	if err := json.Unmarshal(plaintext, &s.values); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return s, nil
+}
+
+// Get returns the value of the named secret
+func (s *Store) Get(name string) (string, bool) {
+	value, ok := s.values[name]
+	return value, ok
+}
+
+// Set sets the value of the named secret. The store must be saved for the change to persist.
+func (s *Store) Set(name, value string) {
+	s.values[name] = value
+}
+
+// Names returns the names of all secrets in the store, sorted
+func (s *Store) Names() []string {
+	var names []string
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save encrypts the secrets and writes them to the file from which they were loaded.
+// A new key is generated if none exists.
+func (s *Store) Save() error {
+	key, err := s.loadOrCreateKey()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return errors.WithMessage(err, s.keyPath)
+	}
+	plaintext, err := json.Marshal(s.values)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errors.WithStack(err)
+	}
+	content := gcm.Seal(nonce, nonce, plaintext, nil)
+	return errors.WithStack(ioutil.WriteFile(s.path, content, 0600))
+}
+
+func (s *Store) loadOrCreateKey() ([]byte, error) {
+	key, err := ioutil.ReadFile(s.keyPath)
+	if err == nil {
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.WithStack(err)
+	}
+	key = make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.keyPath), 0700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return key, errors.WithStack(ioutil.WriteFile(s.keyPath, key, 0600))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, errors.Errorf("invalid key, expected %v bytes", keySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	return gcm, errors.WithStack(err)
+}
@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	must "github.com/theothertomelliott/must"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	must.BeNoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, FileName)
+	keyPath := filepath.Join(dir, "home", "secrets.key")
+
+	store, err := Load(path, keyPath)
+	must.BeNoError(t, err)
+	_, ok := store.Get("db")
+	must.BeEqual(t, false, ok)
+
+	store.Set("db", "s3cret")
+	store.Set("api", "token")
+	must.BeNoError(t, store.Save())
+
+	content, err := ioutil.ReadFile(path)
+	must.BeNoError(t, err)
+	must.BeEqual(t, false, strings.Contains(string(content), "s3cret"))
+
+	loaded, err := Load(path, keyPath)
+	must.BeNoError(t, err)
+	value, ok := loaded.Get("db")
+	must.BeEqual(t, true, ok)
+	must.BeEqual(t, "s3cret", value)
+	must.BeEqual(t, []string{"api", "db"}, loaded.Names())
+
+	// A different key cannot decrypt the file
+	must.BeNoError(t, os.Remove(keyPath))
+	must.BeNoError(t, (&Store{path: filepath.Join(dir, "other"), keyPath: keyPath, values: map[string]string{}}).Save())
+	_, err = Load(path, keyPath)
+	must.BeEqualErrors(t, errors.New("could not decrypt "+path+": the file is corrupt or was encrypted with a different key"), err)
+
+	must.BeNoError(t, os.Remove(keyPath))
+	_, err = Load(path, keyPath)
+	must.BeEqualErrors(t, errors.New("could not decrypt "+path+": no key found at "+keyPath), err)
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"sort"
 	"strings"
 	"syscall"
 
@@ -34,10 +35,17 @@ type ServiceCommand struct {
 	NedwardVersion string `json:"nedwardVersion"`
 	// Overrides applied by the group under which this service was started
 	Overrides ContextOverride `json:"overrides,omitempty"`
+	// Profile selected when this instance was launched, if any
+	Profile string `json:"profile,omitempty"`
 	// Identifier for this instance of the service
 	InstanceId string
 
 	Logger common.Logger `json:"-"`
+
+	// Variables read from each env file by ResolveEnv, by path
+	fileEnv map[string][]string
+	// Values of secrets resolved by ResolveEnv
+	secretValues map[EnvSecret]string
 }
 
 // LoadServiceCommand loads the command to control the specified service
@@ -51,7 +59,7 @@ func LoadServiceCommand(service *ServiceConfig, overrides ContextOverride) (comm
 		command.Service = service
 		command.Logger = service.Logger
 		command.NedwardVersion = common.NedwardVersion
-		command.Overrides = command.Overrides.Merge(overrides)
+		command.Overrides = overrides.Merge(command.Overrides)
 		err = command.checkPid()
 	}()
 
@@ -76,27 +84,6 @@ func LoadServiceCommand(service *ServiceConfig, overrides ContextOverride) (comm
 	return command, nil
 }
 
-// Env provides the combined environment variables for this service command
-func (c *ServiceCommand) Env() []string {
-	return append(c.Service.Env, c.Overrides.Env...)
-}
-
-// Getenv returns the environment variable value for the provided key, if present.
-// Env overrides are consulted first, followed by service env settings, then the os Env.
-func (c *ServiceCommand) Getenv(key string) string {
-	for _, env := range c.Overrides.Env {
-		if strings.HasPrefix(env, key+"=") {
-			return strings.Replace(env, key+"=", "", 1)
-		}
-	}
-	for _, env := range c.Service.Env {
-		if strings.HasPrefix(env, key+"=") {
-			return strings.Replace(env, key+"=", "", 1)
-		}
-	}
-	return os.Getenv(key)
-}
-
 func (c *ServiceCommand) checkPid() error {
 	if c == nil || c.Pid == 0 {
 		return nil
@@ -140,11 +127,11 @@ func (c *ServiceCommand) printf(format string, v ...interface{}) {
 	if c.Logger == nil {
 		return
 	}
-	c.Logger.Printf(format, v...)
+	c.Logger.Printf("%s", c.Redact(fmt.Sprintf(format, v...)))
 }
 
 func (c *ServiceCommand) createScript(content string, scriptType string) (*os.File, error) {
-	file, err := os.Create(path.Join(home.NedwardConfig.ScriptDir, c.Service.Name+"-"+scriptType))
+	file, err := os.Create(path.Join(home.NedwardConfig.ScriptDir, c.Service.fileName()+"-"+scriptType))
 	if err != nil {
 		return nil, err
 	}
@@ -162,7 +149,7 @@ func (c *ServiceCommand) createScript(content string, scriptType string) (*os.Fi
 func (c *ServiceCommand) deleteScript(scriptType string) error {
 	return errors.WithStack(
 		os.Remove(
-			path.Join(home.NedwardConfig.ScriptDir, c.Service.Name+"-"+scriptType),
+			path.Join(home.NedwardConfig.ScriptDir, c.Service.fileName()+"-"+scriptType),
 		),
 	)
 }
@@ -201,7 +188,7 @@ func (c *ServiceCommand) BuildWithTracker(workingDir string, force bool, task tr
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		job.SetState(tracker.TaskStateFailed, err.Error(), string(out))
+		job.SetState(tracker.TaskStateFailed, c.redactAll([]string{err.Error(), string(out)})...)
 		return errors.WithMessage(err, "running build command")
 	}
 
@@ -210,6 +197,9 @@ func (c *ServiceCommand) BuildWithTracker(workingDir string, force bool, task tr
 }
 
 func (c *ServiceCommand) constructCommand(workingDir string, command string) (*exec.Cmd, error) {
+	if err := c.ResolveEnv(); err != nil {
+		return nil, errors.WithStack(err)
+	}
 	command, cmdArgs, err := commandline.ParseCommand(os.Expand(command, c.Getenv))
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -217,6 +207,7 @@ func (c *ServiceCommand) constructCommand(workingDir string, command string) (*e
 
 	cmd := exec.Command(command, cmdArgs...)
 	cmd.Dir = buildAbsPath(workingDir, c.Service.Path)
+	cmd.Env = c.environ()
 	return cmd, nil
 }
 
@@ -224,7 +215,7 @@ func (c *ServiceCommand) constructCommand(workingDir string, command string) (*e
 // Will block until the service is known to have started successfully.
 // If the service fails to launch, an error will be returned.
 func (c *ServiceCommand) StartAsync(cfg OperationConfig, task tracker.Task) error {
-	if c.Service.Commands.Launch == "" {
+	if !c.Service.IsLaunchable() {
 		return nil
 	}
 
@@ -258,8 +249,12 @@ func (c *ServiceCommand) StartAsync(cfg OperationConfig, task tracker.Task) erro
 		return errors.WithStack(err)
 	}
 
-	cmd.Env = append(os.Environ(), c.Overrides.Env...)
-	cmd.Env = append(cmd.Env, c.Service.Env...)
+	err = c.ResolveEnv()
+	if err != nil {
+		startTask.SetState(tracker.TaskStateFailed, err.Error())
+		return errors.WithStack(err)
+	}
+	cmd.Env = c.environ()
 
 	c.printf("starting command\n")
 	err = cmd.Start()
@@ -270,6 +265,7 @@ func (c *ServiceCommand) StartAsync(cfg OperationConfig, task tracker.Task) erro
 	}
 
 	c.Pid = cmd.Process.Pid
+	c.Profile = c.Service.Profile
 
 	c.printf("%v has PID: %d.\n", c.Service.Name, c.Pid)
 
@@ -290,7 +286,7 @@ func (c *ServiceCommand) StartAsync(cfg OperationConfig, task tracker.Task) erro
 	if readingErr != nil {
 		startTask.SetState(tracker.TaskStateFailed, "Could not read log", readingErr.Error(), fmt.Sprint("Original error: ", err.Error()))
 	} else {
-		startTask.SetState(tracker.TaskStateFailed, log...)
+		startTask.SetState(tracker.TaskStateFailed, c.redactAll(log)...)
 	}
 	stopErr := c.Service.doStop(cfg, c.Overrides, task.Child("Cleanup"))
 	if stopErr != nil {
@@ -326,6 +322,17 @@ func (c *ServiceCommand) getLaunchCommand(cfg OperationConfig) (*exec.Cmd, error
 	if cfg.LogFile != "" {
 		cmdArgs = append(cmdArgs, "--logfile", cfg.LogFile)
 	}
+	var varNames []string
+	for name := range cfg.Vars {
+		varNames = append(varNames, name)
+	}
+	sort.Strings(varNames)
+	for _, name := range varNames {
+		cmdArgs = append(cmdArgs, "--var", name+"="+cfg.Vars[name])
+	}
+	if cfg.Profile != "" {
+		cmdArgs = append(cmdArgs, "--profile", cfg.Profile)
+	}
 	c.printf("Launching runner with args: %v", cmdArgs)
 	cmd := exec.Command(command, cmdArgs...)
 	cmd.Dir = buildAbsPath(cfg.WorkingDir, c.Service.Path)
@@ -345,7 +352,7 @@ func (c *ServiceCommand) RunStopScript(workingDir string) ([]byte, error) {
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return out, errors.WithStack(err)
+		return []byte(c.Redact(string(out))), errors.WithStack(err)
 	}
 	return nil, nil
 }
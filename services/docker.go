@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/nedscode/nedward/commandline"
+	"github.com/pkg/errors"
+)
+
+// ServiceTypeDocker identifies a service that is run as a named Docker container
+const ServiceTypeDocker = "docker"
+
+// DockerConfig defines the container to be run for a service of type "docker"
+type DockerConfig struct {
+	// Image from which the container will be created
+	Image string `json:"image"`
+	// Name for the container. Defaults to "nedward-" followed by the service name.
+	Container string `json:"container,omitempty"`
+	// Ports to publish, in the form accepted by `docker run -p`, for example "8080:80"
+	Ports []string `json:"ports,omitempty"`
+	// Additional arguments to pass to `docker run`
+	Args []string `json:"args,omitempty"`
+}
+
+// ContainerState provides the subset of `docker inspect` output used to track a container
+type ContainerState struct {
+	Running bool
+	Pid     int
+	Ports   []string
+}
+
+// IsDocker returns true if this service is run as a Docker container
+func (c *ServiceConfig) IsDocker() bool {
+	return c.Type == ServiceTypeDocker
+}
+
+// IsLaunchable returns true if this service has something to launch, either
+// a launch command or a container.
+func (c *ServiceConfig) IsLaunchable() bool {
+	return c.Commands.Launch != "" || c.IsDocker()
+}
+
+// ContainerName returns the name of the container tracked for this service
+func (c *ServiceConfig) ContainerName() string {
+	if c.Docker != nil && c.Docker.Container != "" {
+		return c.Docker.Container
+	}
+	return "nedward-" + c.fileName()
+}
+
+// DockerRunArgs returns the arguments to `docker` that will start the container for
+// this service in the background, with the provided environment passed into the container.
+func (c *ServiceConfig) DockerRunArgs(env []string) ([]string, error) {
+	args := []string{"run", "-d", "--name", c.ContainerName()}
+	for _, port := range c.Docker.Ports {
+		args = append(args, "-p", port)
+	}
+	for _, e := range env {
+		args = append(args, "-e", e)
+	}
+	args = append(args, c.Docker.Args...)
+	args = append(args, c.Docker.Image)
+	if c.Commands.Launch != "" {
+		command, cmdArgs, err := commandline.ParseCommand(os.ExpandEnv(c.Commands.Launch))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		args = append(args, command)
+		args = append(args, cmdArgs...)
+	}
+	return args, nil
+}
+
+// DockerLogsCommand returns a command that will follow the logs of the container for
+// this service until it stops.
+func (c *ServiceConfig) DockerLogsCommand() *exec.Cmd {
+	return exec.Command("docker", "logs", "-f", c.ContainerName())
+}
+
+// InspectContainer obtains the current state of the container for this service.
+func (c *ServiceConfig) InspectContainer() (*ContainerState, error) {
+	out, err := docker("inspect", c.ContainerName())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parseInspect(out)
+}
+
+// StopContainer stops the container for this service, allowing it to shut down gracefully.
+func (c *ServiceConfig) StopContainer() error {
+	_, err := docker("stop", c.ContainerName())
+	return errors.WithStack(err)
+}
+
+// KillContainer sends a kill signal to the container for this service.
+func (c *ServiceConfig) KillContainer() error {
+	_, err := docker("kill", c.ContainerName())
+	return errors.WithStack(err)
+}
+
+// RemoveContainer removes the container for this service, whether or not it is running.
+func (c *ServiceConfig) RemoveContainer() error {
+	_, err := docker("rm", "-f", c.ContainerName())
+	return errors.WithStack(err)
+}
+
+func docker(args ...string) ([]byte, error) {
+	cmd := exec.Command("docker", args...)
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+	out, err := cmd.Output()
+	if err != nil {
+		return out, errors.WithMessage(err, fmt.Sprintf("docker %v: %v", args[0], strings.TrimSpace(errBuf.String())))
+	}
+	return out, nil
+}
+
+func parseInspect(raw []byte) (*ContainerState, error) {
+	var inspected []struct {
+		State struct {
+			Running bool
+			Pid     int
+		}
+		NetworkSettings struct {
+			Ports map[string][]struct {
+				HostPort string
+			}
+		}
+	}
+	err := json.Unmarshal(raw, &inspected)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse container state")
+	}
+	if len(inspected) == 0 {
+		return nil, errors.New("container not found")
+	}
+
+	state := &ContainerState{
+		Running: inspected[0].State.Running,
+		Pid:     inspected[0].State.Pid,
+	}
+	var knownPorts = make(map[string]struct{})
+	for _, bindings := range inspected[0].NetworkSettings.Ports {
+		for _, binding := range bindings {
+			if _, ok := knownPorts[binding.HostPort]; binding.HostPort != "" && !ok {
+				knownPorts[binding.HostPort] = struct{}{}
+				state.Ports = append(state.Ports, binding.HostPort)
+			}
+		}
+	}
+	sort.Strings(state.Ports)
+	return state, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	must "github.com/theothertomelliott/must"
+)
+
+// useStandinDocker puts the stand-in docker binary from testdata on the PATH, returning
+// the path to the log of its invocations and a function to restore the environment.
+func useStandinDocker(t *testing.T) (string, func()) {
+	standinDir, err := filepath.Abs("testdata/docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logDir, err := ioutil.TempDir("", "docker-standin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(logDir, "invocations")
+
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", standinDir+string(os.PathListSeparator)+oldPath)
+	os.Setenv("DOCKER_STANDIN_LOG", logPath)
+	return logPath, func() {
+		os.Setenv("PATH", oldPath)
+		os.Unsetenv("DOCKER_STANDIN_LOG")
+		os.RemoveAll(logDir)
+	}
+}
+
+func invocations(t *testing.T, logPath string) []string {
+	raw, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(raw)), "\n")
+}
+
+func TestDockerRunArgs(t *testing.T) {
+	var tests = []struct {
+		name    string
+		service *ServiceConfig
+		env     []string
+		outArgs []string
+	}{
+		{
+			name: "defaults",
+			service: &ServiceConfig{
+				Name: "web",
+				Type: ServiceTypeDocker,
+				Docker: &DockerConfig{
+					Image: "web:nedward",
+				},
+			},
+			outArgs: []string{"run", "-d", "--name", "nedward-web", "web:nedward"},
+		},
+		{
+			name: "ports, env and command",
+			service: &ServiceConfig{
+				Name: "web",
+				Type: ServiceTypeDocker,
+				Commands: ServiceConfigCommands{
+					Launch: "serve --verbose",
+				},
+				Docker: &DockerConfig{
+					Image:     "web:nedward",
+					Container: "mycontainer",
+					Ports:     []string{"8080:80"},
+					Args:      []string{"--rm"},
+				},
+			},
+			env: []string{"KEY=value"},
+			outArgs: []string{
+				"run", "-d", "--name", "mycontainer",
+				"-p", "8080:80",
+				"-e", "KEY=value",
+				"--rm",
+				"web:nedward", "serve", "--verbose",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args, err := test.service.DockerRunArgs(test.env)
+			must.BeEqual(t, test.outArgs, args)
+			must.BeEqualErrors(t, nil, err)
+		})
+	}
+}
+
+func TestContainerCommands(t *testing.T) {
+	logPath, restore := useStandinDocker(t)
+	defer restore()
+
+	service := &ServiceConfig{
+		Name: "web",
+		Type: ServiceTypeDocker,
+		Docker: &DockerConfig{
+			Image: "web:nedward",
+		},
+	}
+
+	state, err := service.InspectContainer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	must.BeEqual(t, &ContainerState{
+		Running: true,
+		Pid:     1234,
+		Ports:   []string{"8080", "8443"},
+	}, state)
+
+	must.BeEqualErrors(t, nil, service.StopContainer())
+	must.BeEqualErrors(t, nil, service.KillContainer())
+	must.BeEqualErrors(t, nil, service.RemoveContainer())
+	must.BeEqual(t, []string{
+		"inspect nedward-web",
+		"stop nedward-web",
+		"kill nedward-web",
+		"rm -f nedward-web",
+	}, invocations(t, logPath))
+
+	service.Docker.Container = "missing"
+	_, err = service.InspectContainer()
+	must.BeEqualErrors(t, errors.New("docker inspect: Error: No such object: missing: exit status 1"), err)
+}
+
+func TestValidateServiceType(t *testing.T) {
+	var tests = []struct {
+		name   string
+		config string
+		outErr error
+	}{
+		{
+			name:   "docker",
+			config: `{"name": "web", "type": "docker", "docker": {"image": "web"}}`,
+		},
+		{
+			name:   "docker without image",
+			config: `{"name": "web", "type": "docker"}`,
+			outErr: errors.New("service web: an image is required for services of type docker"),
+		},
+		{
+			name:   "unknown type",
+			config: `{"name": "web", "type": "vm"}`,
+			outErr: errors.New("service web: unknown type: vm"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var service ServiceConfig
+			err := service.UnmarshalJSON([]byte(test.config))
+			must.BeEqualErrors(t, test.outErr, err)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EnvLayer is a set of environment variables from a single source, such as a group or service
+type EnvLayer struct {
+	// Description of where the variables were set, for example "group backend"
+	Source string
+	// Variables in the form KEY=VALUE
+	Env []string `json:",omitempty"`
+	// Paths to files of environment variables
+	EnvFile []string `json:",omitempty"`
+	// Variables whose values are resolved when a service is launched
+	Secrets []EnvSecret `json:",omitempty"`
+}
+
+// EnvVar is the effective value of an environment variable, with a description of where it was set
+type EnvVar struct {
+	Key    string
+	Value  string
+	Source string
+	// Path to the env file from which the variable was read, if any
+	File string
+	// Set if the value was resolved from a command or the secrets file, and should not be displayed
+	Secret bool
+}
+
+// Sources of environment variables that are not groups
+const (
+	EnvSourceOS          = "os"
+	EnvSourceGlobal      = "global"
+	EnvSourceService     = "service"
+	EnvSourceCommandLine = "command line"
+)
+
+// EnvLayers returns the layers of environment variables for this command, in increasing order
+// of precedence: global env, followed by group env from the outermost group to the innermost,
+// the env of the service itself and finally env set on the command line.
+// All layers take precedence over the os Env.
+func (c *ServiceCommand) EnvLayers() []EnvLayer {
+	layers := []EnvLayer{
+		{
+			Source:  EnvSourceGlobal,
+			Env:     c.Service.GlobalEnv,
+			EnvFile: c.Service.GlobalEnvFile,
+			Secrets: c.Service.GlobalSecretEnv,
+		},
+	}
+	layers = append(layers, c.Overrides.GroupEnv...)
+	return append(layers,
+		EnvLayer{Source: EnvSourceService, Env: c.Service.Env, EnvFile: c.Service.EnvFile, Secrets: c.Service.SecretEnv},
+		EnvLayer{Source: EnvSourceCommandLine, Env: c.Overrides.Env},
+	)
+}
+
+// ResolveEnv reads the env files and resolves the values of secrets for all layers, so they are
+// included in the env for commands. This is done each time it is called, so changes are picked
+// up by each build and launch.
+func (c *ServiceCommand) ResolveEnv() error {
+	fileEnv := make(map[string][]string)
+	secretValues := make(map[EnvSecret]string)
+	for _, layer := range c.EnvLayers() {
+		for _, path := range layer.EnvFile {
+			if _, loaded := fileEnv[path]; loaded {
+				continue
+			}
+			env, err := ReadEnvFile(path)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			fileEnv[path] = env
+		}
+		if err := c.resolveSecrets(layer.Secrets, secretValues); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	c.fileEnv = fileEnv
+	c.secretValues = secretValues
+	return nil
+}
+
+// envEntry is an unexpanded variable from a layer
+type envEntry struct {
+	key, value string
+	// Path to the env file from which the variable was read, if any
+	file   string
+	secret bool
+}
+
+// layerEntries returns the variables set by a layer, in increasing order of precedence.
+// Variables set inline take precedence over those read from env files, and variables from
+// later files take precedence over earlier ones. Secrets take precedence over both, and are
+// only included once resolved.
+func (c *ServiceCommand) layerEntries(layer EnvLayer) []envEntry {
+	var entries []envEntry
+	for _, path := range layer.EnvFile {
+		for _, env := range c.fileEnv[path] {
+			parts := strings.SplitN(env, "=", 2)
+			entries = append(entries, envEntry{key: parts[0], value: parts[1], file: path})
+		}
+	}
+	for _, env := range layer.Env {
+		parts := strings.SplitN(env, "=", 2)
+		entry := envEntry{key: parts[0]}
+		if len(parts) > 1 {
+			entry.value = parts[1]
+		}
+		entries = append(entries, entry)
+	}
+	for _, secret := range layer.Secrets {
+		if value, resolved := c.secretValues[secret]; resolved {
+			entries = append(entries, envEntry{key: secret.Name, value: value, secret: true})
+		}
+	}
+	return entries
+}
+
+// lookupEnv returns the unexpanded value for key from the highest precedence layer below the
+// layer with index below, along with the index of the layer in which it was found.
+func (c *ServiceCommand) lookupEnv(layers []EnvLayer, key string, below int) (envEntry, int, bool) {
+	for i := below - 1; i >= 0; i-- {
+		entries := c.layerEntries(layers[i])
+		for j := len(entries) - 1; j >= 0; j-- {
+			if entries[j].key == key {
+				return entries[j], i, true
+			}
+		}
+	}
+	return envEntry{}, 0, false
+}
+
+// envRef identifies a variable being expanded, to detect cycles
+type envRef struct {
+	key   string
+	below int
+}
+
+// Getenv returns the environment variable value for the provided key, if present.
+// Layers are consulted in order of precedence, as described for EnvLayers, then the os Env.
+//
+// References to other variables are expanded. A reference to the variable being defined,
+// such as PATH=/opt/bin:$PATH, refers to its value in the layers of lower precedence.
+func (c *ServiceCommand) Getenv(key string) string {
+	layers := c.EnvLayers()
+	return c.getenv(layers, key, len(layers), map[envRef]bool{})
+}
+
+func (c *ServiceCommand) getenv(layers []EnvLayer, key string, below int, expanding map[envRef]bool) string {
+	entry, layer, found := c.lookupEnv(layers, key, below)
+	ref := envRef{key: key, below: below}
+	if !found || expanding[ref] {
+		return os.Getenv(key)
+	}
+	if entry.secret {
+		return entry.value
+	}
+	expanding[ref] = true
+	defer delete(expanding, ref)
+	return os.Expand(entry.value, func(name string) string {
+		if name == key {
+			return c.getenv(layers, name, layer, expanding)
+		}
+		return c.getenv(layers, name, len(layers), expanding)
+	})
+}
+
+// Environment returns the effective value of each variable set for this command, sorted by key,
+// along with the source of each value. Variables from the os Env are only included if all is true.
+func (c *ServiceCommand) Environment(all bool) []EnvVar {
+	layers := c.EnvLayers()
+	sources := make(map[string]EnvVar)
+	if all {
+		for _, env := range os.Environ() {
+			key := strings.SplitN(env, "=", 2)[0]
+			sources[key] = EnvVar{Key: key, Source: EnvSourceOS}
+		}
+	}
+	for _, layer := range layers {
+		for _, entry := range c.layerEntries(layer) {
+			sources[entry.key] = EnvVar{Key: entry.key, Source: layer.Source, File: entry.file, Secret: entry.secret}
+		}
+	}
+
+	var vars []EnvVar
+	for key, v := range sources {
+		v.Value = c.getenv(layers, key, len(layers), map[envRef]bool{})
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Key < vars[j].Key
+	})
+	return vars
+}
+
+// Env provides the effective values of the environment variables set for this service command,
+// in the form KEY=VALUE.
+func (c *ServiceCommand) Env() []string {
+	var env []string
+	for _, v := range c.Environment(false) {
+		env = append(env, v.Key+"="+v.Value)
+	}
+	return env
+}
+
+// environ returns the os Env, updated with the effective values of the variables set for
+// this service command, for use by commands run for this service.
+func (c *ServiceCommand) environ() []string {
+	return append(os.Environ(), c.Env()...)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EnvFiles lists paths to files of environment variables. In config, it may be specified as a
+// single path or an array of paths.
+type EnvFiles []string
+
+// UnmarshalJSON accepts either a single path or an array of paths
+func (e *EnvFiles) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*e = EnvFiles{path}
+		return nil
+	}
+	var paths []string
+	if err := json.Unmarshal(data, &paths); err != nil {
+		return errors.New("env_file must be a path or an array of paths")
+	}
+	*e = EnvFiles(paths)
+	return nil
+}
+
+var envKeyExpr = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// ReadEnvFile reads environment variables from a dotenv-style file, returning them in the
+// form KEY=VALUE.
+//
+// Each line is a KEY=VALUE pair, optionally prefixed with "export". Blank lines and lines
+// starting with # are ignored. Values may be wrapped in single or double quotes; escape
+// sequences such as \n are interpreted in double quoted values only. Unquoted values end
+// at a # preceded by whitespace.
+func ReadEnvFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer file.Close()
+
+	var env []string
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("%v:%v: expected KEY=VALUE, found %q", path, lineNumber, line)
+		}
+		key := strings.TrimSpace(parts[0])
+		if !envKeyExpr.MatchString(key) {
+			return nil, errors.Errorf("%v:%v: invalid variable name %q", path, lineNumber, key)
+		}
+		value, err := parseEnvValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, errors.Errorf("%v:%v: %v", path, lineNumber, err)
+		}
+		env = append(env, key+"="+value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return env, nil
+}
+
+func parseEnvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	quote := value[0]
+	if quote != '"' && quote != '\'' {
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		return value, nil
+	}
+
+	var unquoted strings.Builder
+	for i := 1; i < len(value); i++ {
+		ch := value[i]
+		switch {
+		case ch == quote:
+			rest := strings.TrimSpace(value[i+1:])
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", errors.Errorf("unexpected %q after quoted value", rest)
+			}
+			return unquoted.String(), nil
+		case ch == '\\' && quote == '"' && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				unquoted.WriteByte('\n')
+			case 't':
+				unquoted.WriteByte('\t')
+			case 'r':
+				unquoted.WriteByte('\r')
+			default:
+				unquoted.WriteByte(value[i])
+			}
+		default:
+			unquoted.WriteByte(ch)
+		}
+	}
+	return "", errors.Errorf("unterminated quoted value %v", value)
+}
+
+// readEnvFiles reads the variables from each of paths in turn
+func readEnvFiles(paths []string) ([]string, error) {
+	var env []string
+	for _, path := range paths {
+		fileEnv, err := ReadEnvFile(path)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		env = append(env, fileEnv...)
+	}
+	return env, nil
+}
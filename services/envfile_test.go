@@ -0,0 +1,56 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	must "github.com/theothertomelliott/must"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	env, err := ReadEnvFile(filepath.Join("testdata", "envfile", "service.env"))
+	must.BeNoError(t, err)
+	must.BeEqual(t, []string{
+		"NAME=file",
+		"DB_URL=postgres://localhost/db?sslmode=disable",
+		"GREETING=hello # not a comment",
+		"MULTILINE=line1\nline2",
+		"LEVEL=debug",
+		"EMPTY=",
+	}, env)
+}
+
+func TestReadEnvFileErrors(t *testing.T) {
+	var tests = []struct {
+		file     string
+		expected error
+	}{
+		{
+			file:     "malformed.env",
+			expected: errors.New(`testdata/envfile/malformed.env:2: expected KEY=VALUE, found "this is not valid"`),
+		},
+		{
+			file:     "unterminated.env",
+			expected: errors.New(`testdata/envfile/unterminated.env:2: unterminated quoted value "unterminated`),
+		},
+		{
+			file:     "badkey.env",
+			expected: errors.New(`testdata/envfile/badkey.env:1: invalid variable name "1BAD"`),
+		},
+	}
+	for _, test := range tests {
+		_, err := ReadEnvFile(filepath.Join("testdata", "envfile", test.file))
+		must.BeEqualErrors(t, test.expected, err, test.file)
+	}
+}
+
+func TestEnvFilesUnmarshal(t *testing.T) {
+	var files EnvFiles
+	must.BeNoError(t, json.Unmarshal([]byte(`".env"`), &files))
+	must.BeEqual(t, EnvFiles{".env"}, files)
+	must.BeNoError(t, json.Unmarshal([]byte(`[".env", "secrets.env"]`), &files))
+	must.BeEqual(t, EnvFiles{".env", "secrets.env"}, files)
+	must.BeEqualErrors(t, errors.New("env_file must be a path or an array of paths"), json.Unmarshal([]byte(`1`), &files))
+}
@@ -29,6 +29,10 @@ type ServiceGroupConfig struct {
 
 	// Environment variables to be passed to all child services
 	Env []string
+	// Paths to files of environment variables to be passed to all child services
+	EnvFile []string
+	// Variables to be passed to all child services, whose values are resolved at launch
+	SecretEnv []EnvSecret
 
 	Logger common.Logger
 }
@@ -87,7 +91,9 @@ func (c *ServiceGroupConfig) Build(cfg OperationConfig, overrides ContextOverrid
 
 func (c *ServiceGroupConfig) getOverrides(o ContextOverride) ContextOverride {
 	override := ContextOverride{
-		Env: c.Env,
+		GroupEnv: []EnvLayer{
+			{Source: "group " + c.Name, Env: c.Env, EnvFile: c.EnvFile, Secrets: c.SecretEnv},
+		},
 	}
 	return override.Merge(o)
 }
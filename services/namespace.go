@@ -0,0 +1,80 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NamespaceSeparator separates the namespace of an imported service or group from its name,
+// for example "payments/api"
+const NamespaceSeparator = "/"
+
+// Qualify returns name prefixed with namespace
+func Qualify(namespace, name string) string {
+	return namespace + NamespaceSeparator + name
+}
+
+// ResolveName returns the entry in names that name refers to. This is either an exact match, or
+// the only entry of which name is the unqualified form, such as "api" for "payments/api".
+// Returns an empty string if nothing matches, or an error if name could refer to more than one entry.
+func ResolveName(name string, names []string) (string, error) {
+	var matches []string
+	for _, candidate := range names {
+		if candidate == name {
+			return name, nil
+		}
+		if strings.HasSuffix(candidate, NamespaceSeparator+name) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	}
+	sort.Strings(matches)
+	return "", errors.Errorf("%v is ambiguous, could be any of: %v", name, strings.Join(matches, ", "))
+}
+
+// FindServiceOrGroup returns the service or group from the provided maps that matches name, by name or
+// alias. Names of services and groups from namespaced imports may be given in full, or unqualified
+// where this is unambiguous. Returns nil if nothing matches.
+func FindServiceOrGroup(name string, serviceMap map[string]*ServiceConfig, groupMap map[string]*ServiceGroupConfig) (ServiceOrGroup, error) {
+	var byName = make(map[string]ServiceOrGroup)
+	for _, group := range groupMap {
+		for _, n := range append([]string{group.Name}, group.Aliases...) {
+			byName[n] = group
+		}
+	}
+	for _, service := range serviceMap {
+		for _, n := range append([]string{service.Name}, service.Aliases...) {
+			byName[n] = service
+		}
+	}
+	if sg, ok := byName[name]; ok {
+		return sg, nil
+	}
+
+	// Names and aliases of the same service or group are not ambiguous
+	var matches = make(map[string]ServiceOrGroup)
+	for n, sg := range byName {
+		if strings.HasSuffix(n, NamespaceSeparator+name) {
+			matches[sg.GetName()] = sg
+		}
+	}
+	if len(matches) > 1 {
+		var names []string
+		for n := range matches {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, errors.Errorf("%v is ambiguous, could be any of: %v", name, strings.Join(names, ", "))
+	}
+	for _, sg := range matches {
+		return sg, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ServiceProfile holds alternate settings for a service, applied when the named profile is
+// selected, for example with `nedward start --profile debug`.
+type ServiceProfile struct {
+	// Commands replacing those of the service. Commands not set here are unchanged.
+	Commands *ServiceConfigCommands `json:"commands,omitempty"`
+	// Environment variables added to those of the service, taking precedence over them
+	Env []string `json:"env,omitempty"`
+	// Env files read after those of the service
+	EnvFile EnvFiles `json:"env_file,omitempty"`
+	// Variables whose values are resolved at launch, set as objects in env
+	SecretEnv []EnvSecret `json:"-"`
+	// Checks replacing those of the service
+	LaunchChecks *LaunchChecks `json:"launch_checks,omitempty"`
+	// Watch settings replacing those of the service
+	WatchJSON json.RawMessage `json:"watch,omitempty"`
+}
+
+// UnmarshalJSON separates secrets from variables set inline in the env array
+func (p *ServiceProfile) UnmarshalJSON(data []byte) error {
+	type Alias ServiceProfile
+	aux := &struct {
+		Env []json.RawMessage `json:"env,omitempty"`
+		*Alias
+	}{
+		Alias: (*Alias)(p),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	p.Env, p.SecretEnv, err = DecodeEnv(aux.Env)
+	return errors.WithStack(err)
+}
+
+// MarshalJSON writes secrets to the env array alongside variables set inline
+func (p ServiceProfile) MarshalJSON() ([]byte, error) {
+	type Alias ServiceProfile
+	return json.Marshal(&struct {
+		Env []interface{} `json:"env,omitempty"`
+		Alias
+	}{
+		Env:   EncodeEnv(p.Env, p.SecretEnv),
+		Alias: Alias(p),
+	})
+}
+
+// ApplyProfile updates this service with the settings from the named profile, if the service
+// defines it, and records the profile as active. Returns true if the service defines the profile.
+func (c *ServiceConfig) ApplyProfile(name string) bool {
+	c.Profile = name
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return false
+	}
+	if profile.Commands != nil {
+		c.Commands = c.Commands.merge(*profile.Commands)
+	}
+	c.Env = append(append([]string{}, c.Env...), profile.Env...)
+	c.EnvFile = append(append(EnvFiles{}, c.EnvFile...), profile.EnvFile...)
+	c.SecretEnv = append(append([]EnvSecret{}, c.SecretEnv...), profile.SecretEnv...)
+	if profile.LaunchChecks != nil {
+		c.LaunchChecks = profile.LaunchChecks
+	}
+	if len(profile.WatchJSON) > 0 {
+		c.WatchJSON = profile.WatchJSON
+	}
+	return true
+}
+
+// merge returns these commands, replaced by any that are set in overrides
+func (c ServiceConfigCommands) merge(overrides ServiceConfigCommands) ServiceConfigCommands {
+	for _, command := range []struct {
+		target *string
+		value  string
+	}{
+		{&c.Install, overrides.Install},
+		{&c.Update, overrides.Update},
+		{&c.Build, overrides.Build},
+		{&c.Launch, overrides.Launch},
+		{&c.Stop, overrides.Stop},
+	} {
+		if command.value != "" {
+			*command.target = command.value
+		}
+	}
+	return c
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/nedscode/nedward/commandline"
+	"github.com/nedscode/nedward/home"
+	"github.com/nedscode/nedward/secrets"
+	"github.com/pkg/errors"
+)
+
+// EnvSecret is an environment variable whose value is resolved when a service is built or launched,
+// so that it need not be stored in plain text in config. In config, it is specified as an object in
+// an env array, for example: {"name": "DB_PASS", "from_command": "pass show db"}
+type EnvSecret struct {
+	Name string `json:"name"`
+	// Command whose output is the value of the variable
+	FromCommand string `json:"from_command,omitempty"`
+	// Name of a value in the encrypted secrets file, managed with `nedward secrets`
+	FromSecret string `json:"from_secret,omitempty"`
+}
+
+// Describe returns a description of where the value of this variable comes from, without revealing the value
+func (e EnvSecret) Describe() string {
+	if e.FromCommand != "" {
+		return "from_command: " + e.FromCommand
+	}
+	return "from_secret: " + e.FromSecret
+}
+
+func (e EnvSecret) validate() error {
+	if e.Name == "" {
+		return errors.New("env: a name is required")
+	}
+	if (e.FromCommand == "") == (e.FromSecret == "") {
+		return errors.Errorf("env %v: exactly one of from_command or from_secret is required", e.Name)
+	}
+	return nil
+}
+
+// DecodeEnv splits the entries of an env array from config into variables set inline, in the form
+// KEY=VALUE, and variables whose values are resolved when a service is launched.
+func DecodeEnv(entries []json.RawMessage) ([]string, []EnvSecret, error) {
+	var env []string
+	var secretEnv []EnvSecret
+	for _, entry := range entries {
+		var s string
+		if err := json.Unmarshal(entry, &s); err == nil {
+			env = append(env, s)
+			continue
+		}
+		var secret EnvSecret
+		if err := json.Unmarshal(entry, &secret); err != nil {
+			return nil, nil, errors.New("env entries must be strings in the form KEY=VALUE or objects")
+		}
+		if err := secret.validate(); err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		secretEnv = append(secretEnv, secret)
+	}
+	return env, secretEnv, nil
+}
+
+// EncodeEnv combines variables set inline with those resolved at launch, for writing to config
+func EncodeEnv(env []string, secretEnv []EnvSecret) []interface{} {
+	var entries []interface{}
+	for _, e := range env {
+		entries = append(entries, e)
+	}
+	for _, secret := range secretEnv {
+		entries = append(entries, secret)
+	}
+	return entries
+}
+
+// resolveSecrets resolves the values of the given variables, running commands in the
+// directory of the service and reading secrets from the secrets file alongside its config.
+func (c *ServiceCommand) resolveSecrets(secretEnv []EnvSecret, values map[EnvSecret]string) error {
+	var store *secrets.Store
+	for _, secret := range secretEnv {
+		if _, resolved := values[secret]; resolved {
+			continue
+		}
+		if secret.FromCommand != "" {
+			value, err := c.runSecretCommand(secret)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			values[secret] = value
+			continue
+		}
+		if store == nil {
+			var err error
+			store, err = secrets.Load(SecretsPath(c.Service.ConfigFile), home.NedwardConfig.SecretKeyFile)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		value, ok := store.Get(secret.FromSecret)
+		if !ok {
+			return errors.Errorf("env %v: secret %v not found, set it with `nedward secrets set %v`", secret.Name, secret.FromSecret, secret.FromSecret)
+		}
+		values[secret] = value
+	}
+	return nil
+}
+
+func (c *ServiceCommand) runSecretCommand(secret EnvSecret) (string, error) {
+	command, cmdArgs, err := commandline.ParseCommand(secret.FromCommand)
+	if err != nil {
+		return "", errors.WithMessage(err, "env "+secret.Name)
+	}
+	cmd := exec.Command(command, cmdArgs...)
+	cmd.Dir = buildAbsPath(filepath.Dir(c.Service.ConfigFile), c.Service.Path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Errorf("env %v: command %q failed: %v %v", secret.Name, secret.FromCommand, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// SecretsPath returns the path to the secrets file for the config file at configPath
+func SecretsPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), secrets.FileName)
+}
+
+// redactedValue replaces the values of secrets in output
+const redactedValue = "********"
+
+// Redact replaces the values of any resolved secrets in s, so it may be logged or displayed
+func (c *ServiceCommand) Redact(s string) string {
+	for _, value := range c.secretValues {
+		if value != "" {
+			s = strings.Replace(s, value, redactedValue, -1)
+		}
+	}
+	return s
+}
+
+func (c *ServiceCommand) redactAll(messages []string) []string {
+	var redacted []string
+	for _, message := range messages {
+		redacted = append(redacted, c.Redact(message))
+	}
+	return redacted
+}
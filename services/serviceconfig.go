@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -40,15 +41,31 @@ type ServiceConfig struct {
 	Path *string `json:"path,omitempty"`
 	// Does this service require sudo privileges?
 	RequiresSudo bool `json:"requiresSudo,omitempty"`
+	// Type of service. If empty, the launch command is run as a process.
+	Type string `json:"type,omitempty"`
 	// Commands for managing the service
 	Commands ServiceConfigCommands `json:"commands"`
 
+	// Container to be run for a service of type "docker"
+	Docker *DockerConfig `json:"docker,omitempty"`
+
 	// Checks to perform to ensure that a service has started correctly
 	LaunchChecks *LaunchChecks `json:"launch_checks,omitempty"`
 
 	// Env holds environment variables for a service, for example: GOPATH=~/gocode/
 	// These will be added to the vars in the environment under which the Nedward command was run
 	Env []string `json:"env,omitempty"`
+	// Paths to files of environment variables, relative to the config file. Files are read
+	// each time the service is built or launched.
+	EnvFile EnvFiles `json:"env_file,omitempty"`
+
+	// Variables whose values are resolved when the service is launched, set as objects in env
+	SecretEnv []EnvSecret `json:"-"`
+
+	// Environment variables, env files and secrets set for all services in the config
+	GlobalEnv       []string    `json:"-"`
+	GlobalEnvFile   []string    `json:"-"`
+	GlobalSecretEnv []EnvSecret `json:"-"`
 
 	Platform string `json:"platform,omitempty"`
 
@@ -58,9 +75,16 @@ type ServiceConfig struct {
 	// Action for warming up this service
 	Warmup *warmup.Warmup `json:"warmup,omitempty"`
 
+	// Alternate settings for this service, by profile name
+	Profiles map[string]ServiceProfile `json:"profiles,omitempty"`
+	// Name of the profile selected when this service was loaded, if any
+	Profile string `json:"-"`
+
 	// Path to config file from which this service was loaded
 	// This may be the file that imported the config containing the service definition.
 	ConfigFile string `json:"-"`
+	// Path to the config file in which this service was defined
+	SourceFile string `json:"-"`
 
 	// Logger for actions on this service
 	Logger common.Logger `json:"-"`
@@ -89,6 +113,7 @@ func (c *ServiceConfig) UnmarshalJSON(data []byte) error {
 	type Alias ServiceConfig
 	aux := &struct {
 		Properties *ServiceConfigProperties `json:"log_properties,omitempty"`
+		Env        []json.RawMessage        `json:"env,omitempty"`
 		*Alias
 	}{
 		Alias: (*Alias)(c),
@@ -96,6 +121,11 @@ func (c *ServiceConfig) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return errors.Wrap(err, "could not parse service config")
 	}
+	var err error
+	c.Env, c.SecretEnv, err = DecodeEnv(aux.Env)
+	if err != nil {
+		return errors.WithMessage(err, "service "+c.Name)
+	}
 	if aux.Properties != nil {
 		if c.LaunchChecks != nil {
 			c.LaunchChecks.LogText = aux.Properties.Started
@@ -109,8 +139,39 @@ func (c *ServiceConfig) UnmarshalJSON(data []byte) error {
 	return errors.WithStack(c.validate())
 }
 
+// MarshalJSON writes secrets to the env array alongside variables set inline
+func (c *ServiceConfig) MarshalJSON() ([]byte, error) {
+	type Alias ServiceConfig
+	return json.Marshal(&struct {
+		Env []interface{} `json:"env,omitempty"`
+		*Alias
+	}{
+		Env:   EncodeEnv(c.Env, c.SecretEnv),
+		Alias: (*Alias)(c),
+	})
+}
+
 // validate checks if this config is allowed
 func (c *ServiceConfig) validate() error {
+	switch c.Type {
+	case "":
+	case ServiceTypeDocker:
+		if c.Docker == nil || c.Docker.Image == "" {
+			return fmt.Errorf("service %v: an image is required for services of type %v", c.Name, c.Type)
+		}
+	default:
+		return fmt.Errorf("service %v: unknown type: %v", c.Name, c.Type)
+	}
+	if c.LaunchChecks != nil {
+		for _, port := range c.LaunchChecks.Ports {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("service %v: invalid port: %v", c.Name, port)
+			}
+		}
+	}
+	if _, err := c.Watch(); err != nil {
+		return fmt.Errorf("service %v: watch must be a path, or an object with include and exclude paths", c.Name)
+	}
 	return nil
 }
 
@@ -163,6 +224,26 @@ type ServiceWatch struct {
 	ExcludedPaths []string       `json:"exclude,omitempty"`
 }
 
+// Excludes returns the first excluded path that matches the given file, if any.
+// Excluded paths match files beneath them. Paths beginning with "**/" match any file
+// below a directory with a name matching the remainder of the path, such as "**/__pycache__".
+func (w ServiceWatch) Excludes(file string) (string, bool) {
+	for _, excluded := range w.ExcludedPaths {
+		if pattern := strings.TrimPrefix(excluded, "**/"); pattern != excluded {
+			for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(file)), "/") {
+				if matched, _ := filepath.Match(pattern, dir); matched {
+					return excluded, true
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(file, excluded) {
+			return excluded, true
+		}
+	}
+	return "", false
+}
+
 // MatchesPlatform determines whether or not this service can be run on the current OS
 func (c *ServiceConfig) MatchesPlatform() bool {
 	return len(c.Platform) == 0 || c.Platform == runtime.GOOS
@@ -280,7 +361,7 @@ func (c *ServiceConfig) Restart(cfg OperationConfig, overrides ContextOverride,
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	overrides = command.Overrides.Merge(overrides)
+	overrides = command.Overrides
 
 	err = c.doStop(cfg, overrides, task)
 	if err != nil {
@@ -311,7 +392,7 @@ func (c *ServiceConfig) doStop(cfg OperationConfig, overrides ContextOverride, t
 		return nil
 	}
 
-	if c.Commands.Launch == "" {
+	if !c.IsLaunchable() {
 		return nil
 	}
 
@@ -349,6 +430,14 @@ func (c *ServiceConfig) doStop(cfg OperationConfig, overrides ContextOverride, t
 				job.SetState(tracker.TaskStateFailed, err.Error())
 				return nil
 			}
+			if c.IsDocker() {
+				// The runner did not get a chance to stop the container
+				err = c.RemoveContainer()
+				if err != nil {
+					job.SetState(tracker.TaskStateFailed, err.Error())
+					return nil
+				}
+			}
 			if stopped {
 				job.SetState(tracker.TaskStateWarning, "Killed")
 				return nil
@@ -453,6 +542,14 @@ func (c *ServiceConfig) Status() ([]ServiceStatus, error) {
 }
 
 func (c *ServiceConfig) getPorts(proc *process.Process) ([]string, error) {
+	if c.IsDocker() {
+		state, err := c.InspectContainer()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return state.Ports, nil
+	}
+
 	ports, err := c.doGetPorts(proc)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -539,7 +636,13 @@ func (c *ServiceConfig) IsSudo(cfg OperationConfig) bool {
 // GetRunLog returns the path to the run log for this service
 func (c *ServiceConfig) GetRunLog() string {
 	dir := home.NedwardConfig.LogDir
-	return path.Join(dir, c.Name+".log")
+	return path.Join(dir, c.fileName()+".log")
+}
+
+// fileName returns the name of this service in a form that may be used in file and container names,
+// with any namespace separators replaced
+func (c *ServiceConfig) fileName() string {
+	return strings.Replace(c.Name, NamespaceSeparator, "_", -1)
 }
 
 // GetCommand returns the ServiceCommand for this service
@@ -552,7 +655,7 @@ func (c *ServiceConfig) GetCommand(overrides ContextOverride) (*ServiceCommand,
 // that may be configured on a machine.
 // The filename will be based on the service name and the path to its Edward config. It does not include an extension.
 func (c *ServiceConfig) IdentifyingFilename() string {
-	name := c.Name
+	name := c.fileName()
 	hasher := sha1.New()
 	hasher.Write([]byte(c.ConfigFile))
 	sha := base64.URLEncoding.EncodeToString(hasher.Sum(nil))
@@ -582,6 +685,6 @@ func (c *ServiceConfig) getStatePath() string {
 
 func (c *ServiceConfig) GetPidPathLegacy() string {
 	dir := home.NedwardConfig.PidDir
-	name := c.Name
+	name := c.fileName()
 	return path.Join(dir, fmt.Sprintf("%v.pid", name))
 }
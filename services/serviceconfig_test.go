@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	must "github.com/theothertomelliott/must"
+)
+
+func TestWatchExcludes(t *testing.T) {
+	watch := ServiceWatch{
+		ExcludedPaths: []string{"web/node_modules", "**/__pycache__"},
+	}
+
+	var tests = []struct {
+		file     string
+		excluded string
+	}{
+		{file: "web/node_modules/left-pad/index.js", excluded: "web/node_modules"},
+		{file: "app/__pycache__/views.cpython-311.pyc", excluded: "**/__pycache__"},
+		{file: "app/views/__pycache__/index.pyc", excluded: "**/__pycache__"},
+		{file: "app/views.py"},
+		{file: "web/src/index.js"},
+	}
+	for _, test := range tests {
+		excluded, found := watch.Excludes(test.file)
+		must.BeEqual(t, test.excluded, excluded, test.file)
+		must.BeEqual(t, test.excluded != "", found, test.file)
+	}
+}
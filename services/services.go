@@ -34,6 +34,8 @@ type OperationConfig struct {
 	SkipBuild         bool
 	Tags              []string // Tags to pass to `edward run`
 	LogFile           string
+	Vars              map[string]string // Values for config variables to pass to `edward run`
+	Profile           string            // Profile to pass to `edward run`
 }
 
 // IsExcluded returns true if the given service/group is excluded by this OperationConfig.
@@ -65,15 +67,33 @@ type ServiceOrGroup interface {
 // ContextOverride defines overrides for service configuration caused by commandline
 // flags or group configuration.
 type ContextOverride struct {
-	// Overrides to environment variables
-	Env []string
+	// Environment variables set by the groups under which a service was started, from the
+	// outermost group to the innermost
+	GroupEnv []EnvLayer `json:",omitempty"`
+	// Environment variables set on the command line, which take precedence over all others
+	Env []string `json:",omitempty"`
 }
 
+// Merge combines two sets of overrides, where settings in c take precedence over those in m.
+// Group env from c is treated as nested within the groups of m.
 func (c ContextOverride) Merge(m ContextOverride) ContextOverride {
-	// TODO: Ensure that environment vars from c take precedence over m
-	return ContextOverride{
-		Env: append(m.Env, c.Env...),
+	merged := ContextOverride{
+		GroupEnv: append([]EnvLayer{}, m.GroupEnv...),
+		Env:      append(append([]string{}, m.Env...), c.Env...),
 	}
+	for _, layer := range c.GroupEnv {
+		var found bool
+		for _, existing := range merged.GroupEnv {
+			if existing.Source == layer.Source {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.GroupEnv = append(merged.GroupEnv, layer)
+		}
+	}
+	return merged
 }
 
 // CountServices returns the total number of services in the slice of services and groups.
@@ -44,6 +44,13 @@ func WaitUntilLive(command *exec.Cmd, service *ServiceConfig) error {
 				return nil
 			}
 		}
+	} else if service.IsDocker() {
+		service.printf("Waiting for container: %v", service.ContainerName())
+		startCheck = func(cancel <-chan struct{}) error {
+			return errors.WithStack(
+				waitForContainer(cancel, service),
+			)
+		}
 	} else {
 		service.printf("Waiting for any port")
 		startCheck = func(cancel <-chan struct{}) error {
@@ -163,6 +170,28 @@ func waitForListeningPorts(ports []int, cancel <-chan struct{}, command *exec.Cm
 	return errors.New("exited check loop unexpectedly")
 }
 
+func waitForContainer(cancel <-chan struct{}, service *ServiceConfig) error {
+	for true {
+		time.Sleep(100 * time.Millisecond)
+
+		select {
+		case <-cancel:
+			return nil
+		default:
+		}
+
+		// The container may not have been created yet
+		state, err := service.InspectContainer()
+		if err != nil {
+			continue
+		}
+		if state.Running {
+			return nil
+		}
+	}
+	return errors.New("exited check loop unexpectedly")
+}
+
 func waitForAnyPort(cancel <-chan struct{}, command *exec.Cmd) error {
 	for true {
 		time.Sleep(100 * time.Millisecond)